@@ -8,12 +8,15 @@ import training_game_pb2
 RESIDUAL_FILTERS = 16
 RESIDUAL_BLOCKS = 2
 LEARNING_RATE = 0.1
+# Games recorded before board sizes were configurable were always 5x5
+DEFAULT_BOARD_SIZE = 5
 
 
 def get_data():
     inputs = []
     policy_targets = []
     value_targets = []
+    board_dimensions = None
 
     i = 0
     while os.path.exists('training_games/{}'.format(i)):
@@ -22,6 +25,13 @@ def get_data():
             data = f.read()
         training_game = training_game_pb2.TrainingGame()
         training_game.ParseFromString(data)
+        num_rows = training_game.numRows or DEFAULT_BOARD_SIZE
+        num_cols = training_game.numCols or num_rows
+        if board_dimensions is None:
+            board_dimensions = (num_rows, num_cols)
+        elif board_dimensions != (num_rows, num_cols):
+            raise ValueError('Game {} was played on a {}x{} board, expected {}x{}'.format(
+                i, num_rows, num_cols, *board_dimensions))
         move_snapshot = random.choice(training_game.moveSnapshots)
         inputs.append(
             list(move_snapshot.squaresOccupiedByMyself) + \
@@ -30,16 +40,22 @@ def get_data():
         value_targets.append([+1] if move_snapshot.winner == training_game_pb2.TrainingGame.MYSELF else [-1])
         i += 1
 
-    return inputs, policy_targets, value_targets
+    return board_dimensions, inputs, policy_targets, value_targets
 
 
 def main():
     session = tf.Session()
     tf.keras.backend.set_session(session)
 
-    # 25 inputs for the player to move, 25 for the other player
-    board_input = tf.keras.layers.Input(shape=(5*5*2,), dtype='float32', name='boardInput')
-    policy_output = tf.keras.layers.Dense(5*5, activation='softmax', name='policyOutput')(board_input)
+    print('Loading data...')
+    (num_rows, num_cols), inputs, policy_targets, value_targets = get_data()
+    print('...done')
+
+    # One input per square for the player to move, and one per square for the other player.
+    # Player 2 sees the board transposed, but it has the same number of squares.
+    num_squares = num_rows * num_cols
+    board_input = tf.keras.layers.Input(shape=(num_squares*2,), dtype='float32', name='boardInput')
+    policy_output = tf.keras.layers.Dense(num_squares, activation='softmax', name='policyOutput')(board_input)
     value_output = tf.keras.layers.Dense(1, activation='tanh', name='valueOutput')(board_input)
 
     model = tf.keras.models.Model(inputs=[board_input], outputs=[policy_output, value_output])
@@ -50,10 +66,6 @@ def main():
         loss_weights=[1.0, 1.0],
     )
 
-    print('Loading data...')
-    inputs, policy_targets, value_targets = get_data()
-    print('...done')
-
     model.fit(
         [inputs], [policy_targets, value_targets], 
         epochs=10, batch_size=100, validation_split=0.1)
@@ -19,7 +19,7 @@ DESCRIPTOR = _descriptor.FileDescriptor(
   package='hexit',
   syntax='proto3',
   serialized_options=None,
-  serialized_pb=_b('\n\x17src/training_game.proto\x12\x05hexit\"\x9e\x02\n\x0cTrainingGame\x12\x37\n\rmoveSnapshots\x18\x01 \x03(\x0b\x32 .hexit.TrainingGame.MoveSnapshot\x1a\xac\x01\n\x0cMoveSnapshot\x12!\n\x15normalizedVisitCounts\x18\x01 \x03(\x02\x42\x02\x10\x01\x12*\n\x06winner\x18\x02 \x01(\x0e\x32\x1a.hexit.TrainingGame.Player\x12#\n\x17squaresOccupiedByMyself\x18\x03 \x03(\x02\x42\x02\x10\x01\x12(\n\x1csquaresOccupiedByOtherPlayer\x18\x04 \x03(\x02\x42\x02\x10\x01\"&\n\x06Player\x12\n\n\x06MYSELF\x10\x00\x12\x10\n\x0cOTHER_PLAYER\x10\x01\x62\x06proto3')
+  serialized_pb=_b('\n\x17src/training_game.proto\x12\x05hexit\"\xc0\x02\n\x0cTrainingGame\x12\x37\n\rmoveSnapshots\x18\x01 \x03(\x0b\x32 .hexit.TrainingGame.MoveSnapshot\x12\x0f\n\x07numRows\x18\x02 \x01(\r\x12\x0f\n\x07numCols\x18\x03 \x01(\r\x1a\xac\x01\n\x0cMoveSnapshot\x12!\n\x15normalizedVisitCounts\x18\x01 \x03(\x02\x42\x02\x10\x01\x12*\n\x06winner\x18\x02 \x01(\x0e\x32\x1a.hexit.TrainingGame.Player\x12#\n\x17squaresOccupiedByMyself\x18\x03 \x03(\x02\x42\x02\x10\x01\x12(\n\x1csquaresOccupiedByOtherPlayer\x18\x04 \x03(\x02\x42\x02\x10\x01\"&\n\x06Player\x12\n\n\x06MYSELF\x10\x00\x12\x10\n\x0cOTHER_PLAYER\x10\x01\x62\x06proto3')
 )
 
 
@@ -41,8 +41,8 @@ _TRAININGGAME_PLAYER = _descriptor.EnumDescriptor(
   ],
   containing_type=None,
   serialized_options=None,
-  serialized_start=283,
-  serialized_end=321,
+  serialized_start=317,
+  serialized_end=355,
 )
 _sym_db.RegisterEnumDescriptor(_TRAININGGAME_PLAYER)
 
@@ -94,8 +94,8 @@ _TRAININGGAME_MOVESNAPSHOT = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=109,
-  serialized_end=281,
+  serialized_start=143,
+  serialized_end=315,
 )
 
 _TRAININGGAME = _descriptor.Descriptor(
@@ -112,6 +112,20 @@ _TRAININGGAME = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='numRows', full_name='hexit.TrainingGame.numRows', index=1,
+      number=2, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='numCols', full_name='hexit.TrainingGame.numCols', index=2,
+      number=3, type=13, cpp_type=3, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -126,7 +140,7 @@ _TRAININGGAME = _descriptor.Descriptor(
   oneofs=[
   ],
   serialized_start=35,
-  serialized_end=321,
+  serialized_end=355,
 )
 
 _TRAININGGAME_MOVESNAPSHOT.fields_by_name['winner'].enum_type = _TRAININGGAME_PLAYER
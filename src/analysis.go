@@ -0,0 +1,165 @@
+package hexit
+
+import "sort"
+
+// MoveStats are the search statistics of a move at the root of a search tree
+type MoveStats struct {
+	Move Move
+	// Number of visits
+	N uint32
+	// Average value of the move, from the point of view of the player making it
+	Q float32
+	// Policy estimate from the evaluator
+	P float32
+	// Exploration bonus that the search gives the move (the U in Q+U)
+	U float32
+}
+
+func getMoveStats(node *SearchNode, numParentVisits uint32) MoveStats {
+	return MoveStats{
+		Move: node.move,
+		N:    node.n,
+		Q:    node.q,
+		P:    node.p,
+		U:    calculateUctU(node, uint(numParentVisits)),
+	}
+}
+
+// getSortedChildren gets a node's children, with the most visited first. Ties are broken by the policy estimate.
+func getSortedChildren(node *SearchNode) []*SearchNode {
+	children := make([]*SearchNode, 0)
+	for childNode := node.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		children = append(children, childNode)
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		if children[i].n != children[j].n {
+			return children[i].n > children[j].n
+		}
+		return children[i].p > children[j].p
+	})
+	return children
+}
+
+// GetRootMoveStats returns the statistics of every legal move at the root of a search tree,
+// with the most visited moves first. Ties are broken by the policy estimate.
+func GetRootMoveStats(tree *SearchTree) []MoveStats {
+	moveStats := make([]MoveStats, 0)
+	for _, childNode := range getSortedChildren(tree.rootNode) {
+		moveStats = append(moveStats, getMoveStats(childNode, tree.rootNode.n))
+	}
+	return moveStats
+}
+
+// getMostVisitedChild finds the child with the most visits, or nil if no child has been visited
+func getMostVisitedChild(node *SearchNode) *SearchNode {
+	var mostVisitedChild *SearchNode
+	for childNode := node.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		if childNode.n > 0 && (mostVisitedChild == nil || childNode.n > mostVisitedChild.n) {
+			mostVisitedChild = childNode
+		}
+	}
+	return mostVisitedChild
+}
+
+// followPrincipalVariation follows the most visited moves from a node, until it reaches a move that hasn't been searched further
+func followPrincipalVariation(node *SearchNode) []Move {
+	principalVariation := make([]Move, 0)
+	for node = getMostVisitedChild(node); node != nil; node = getMostVisitedChild(node) {
+		principalVariation = append(principalVariation, node.move)
+	}
+	return principalVariation
+}
+
+// GetPrincipalVariation returns the line of play that the search expects: starting from the root,
+// it follows the most visited move until it reaches a move that hasn't been searched further
+func GetPrincipalVariation(tree *SearchTree) []Move {
+	return followPrincipalVariation(tree.rootNode)
+}
+
+// LineMove is a move in a line of play, with its search statistics
+type LineMove struct {
+	// A regular move, or Player 2's decision on move 2
+	Move GameMove
+	// Number of visits to the position after the move
+	N uint32
+	// Average value of the move, from the point of view of the player making it
+	Q float32
+}
+
+// getSwapDecision gets the decision that Player 2 would make on move 2, at the node after Player 1's first stone.
+// The search doesn't visit the decision as a move: Player 2 swaps if the node is good for Player 1, like ShouldSwitchSides.
+func getSwapDecision(node *SearchNode, swapRule SwapRule) LineMove {
+	if node.q > 0 {
+		moveType := SwitchSidesMove
+		if swapRule == SwapPiecesRule {
+			moveType = SwapPiecesMove
+		}
+		// After swapping, Player 2 owns the first stone
+		return LineMove{Move: GameMove{Type: moveType}, N: node.n, Q: node.q}
+	}
+	return LineMove{Move: GameMove{Type: DoNotSwitchSidesMove}, N: node.n, Q: -node.q}
+}
+
+// continueLine follows the most visited moves after a node, until it reaches a move that hasn't been searched further.
+// If the line reaches move 2, it includes Player 2's decision whether to swap.
+func continueLine(line []LineMove, node *SearchNode, isSwapDecisionNext bool, swapRule SwapRule) []LineMove {
+	for {
+		if isSwapDecisionNext {
+			decision := getSwapDecision(node, swapRule)
+			line = append(line, decision)
+			if decision.Move.Type == SwapPiecesMove {
+				// The search only looked at the position where Player 2 didn't swap.
+				// Switching sides leaves the stones where they are, so the line can go on, but swapping pieces doesn't.
+				return line
+			}
+			isSwapDecisionNext = false
+		}
+		node = getMostVisitedChild(node)
+		if node == nil {
+			return line
+		}
+		line = append(line, LineMove{Move: GameMove{Type: RegularMove, Location: node.move}, N: node.n, Q: node.q})
+	}
+}
+
+// getLine gets the line of play that starts with one of the root's moves
+func getLine(tree *SearchTree, childNode *SearchNode) []LineMove {
+	line := make([]LineMove, 0)
+	if CanSwap(tree.game) {
+		line = append(line, getSwapDecision(tree.rootNode, tree.game.SwapRule))
+		if line[0].Move.Type == SwapPiecesMove {
+			return line
+		}
+	}
+	if childNode == nil {
+		return line
+	}
+	line = append(line, LineMove{Move: GameMove{Type: RegularMove, Location: childNode.move}, N: childNode.n, Q: childNode.q})
+	return continueLine(line, childNode, canSwapAfterMove(tree.game), tree.game.SwapRule)
+}
+
+// GetPrincipalLine returns the line of play that the search expects, with the statistics of each move.
+// Unlike GetPrincipalVariation, it includes Player 2's decision on move 2, which DoVisit doesn't visit as a move.
+// If Player 2 would swap pieces, the line stops there, because the search didn't look at the position after swapping pieces.
+func GetPrincipalLine(tree *SearchTree) []LineMove {
+	return getLine(tree, getMostVisitedChild(tree.rootNode))
+}
+
+// GetTopLines returns up to k lines of play, starting with the k most visited moves at the root, like GetPrincipalLine.
+// On move 2, every line starts with Player 2's decision whether to swap. If Player 2 would swap pieces, that's the only line.
+func GetTopLines(tree *SearchTree, k int) [][]LineMove {
+	if k <= 0 {
+		return [][]LineMove{}
+	}
+	lines := make([][]LineMove, 0, k)
+	if CanSwap(tree.game) && getSwapDecision(tree.rootNode, tree.game.SwapRule).Move.Type == SwapPiecesMove {
+		return append(lines, getLine(tree, nil))
+	}
+	for _, childNode := range getSortedChildren(tree.rootNode) {
+		if len(lines) == k {
+			break
+		}
+		lines = append(lines, getLine(tree, childNode))
+	}
+	return lines
+}
@@ -0,0 +1,214 @@
+// Package analysis is a stateless JSON API for analyzing Hex positions with hexit's search.
+// Each request describes a whole position, so the server doesn't keep any state between requests.
+//
+// A server uses NewHandler, and a client uses RequestAnalysis:
+//
+//	err, response := analysis.RequestAnalysis("http://localhost:8080/api/analyze", analysis.Request{
+//		Position: "x----/-o---/-----/-----/-----",
+//		Visits:   800,
+//	})
+package analysis
+
+import (
+	"errors"
+	"fmt"
+
+	hexit "github.com/uyhcire/hexit/src"
+)
+
+// DefaultVisits is the number of visits for requests that don't say
+const DefaultVisits = 1000
+
+// DefaultEvaluator is the evaluator for requests that don't say
+const DefaultEvaluator = "random"
+
+// Request asks for the analysis of a position.
+// Only Position is required. The other fields of the position are inferred from the stones if they're missing.
+type Request struct {
+	// Stones on the board, row by row from the top, as written by hexit.FormatPosition
+	Position string `json:"position"`
+	// The player to move, 1 or 2. Inferred from the number of stones if it's 0.
+	// It has to match the inferred player, unless the players have an unusual number of stones.
+	CurrentPlayer byte `json:"currentPlayer,omitempty"`
+	// Inferred from the number of stones if it's 0. On move 2, Player 2 can swap.
+	// It can only differ from the inferred number when there's one stone: then it's 3 if Player 2 already decided not to swap.
+	MoveNum int `json:"moveNum,omitempty"`
+	// Whether Player 2 switched sides on move 2
+	SwitchedSides bool `json:"switchedSides,omitempty"`
+	// "swap-sides" (the default), "swap-pieces" or "none"
+	SwapRule string `json:"swapRule,omitempty"`
+	// Number of search visits
+	Visits int `json:"visits,omitempty"`
+	// Name of the evaluator, such as "random", "uniform" or "nn"
+	Evaluator string `json:"evaluator,omitempty"`
+}
+
+// MoveAnalysis is the search statistics of one of the moves from the position
+type MoveAnalysis struct {
+	// The move, such as "c3"
+	Move string `json:"move"`
+	// Number of visits
+	N uint32 `json:"n"`
+	// Fraction of the root's visits that went to this move
+	VisitFraction float32 `json:"visitFraction"`
+	// Average value of the move, from the point of view of the player making it
+	Q float32 `json:"q"`
+	// Policy estimate from the evaluator
+	P float32 `json:"p"`
+	// Exploration bonus that the search gives the move
+	U float32 `json:"u"`
+}
+
+// Response is the analysis of a position
+type Response struct {
+	// The move that the engine would play. On move 2, it's "swap" or "swap-pieces" if the engine would swap.
+	BestMove string `json:"bestMove"`
+	// Expected value of the game, from +1 if Player 1 will win to -1 if Player 2 will win
+	ExpectedValue float32 `json:"expectedValue"`
+	// Every legal move, with the most visited moves first
+	Moves []MoveAnalysis `json:"moves"`
+	// The line of play that the search expects, starting with the most visited move.
+	// It includes Player 2's decision on move 2: "swap", "swap-pieces" or "noswap".
+	PrincipalVariation []string `json:"principalVariation"`
+	// Number of search visits that were made
+	Visits int `json:"visits"`
+}
+
+// errorResponse is sent instead of a Response when the request can't be analyzed
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewGameFromRequest sets up the game described by a request
+func NewGameFromRequest(request Request) (error, hexit.Game) {
+	swapRule := hexit.SwapSidesRule
+	if request.SwapRule != "" {
+		var err error
+		err, swapRule = hexit.ParseSwapRule(request.SwapRule)
+		if err != nil {
+			return err, hexit.Game{}
+		}
+	}
+	err, board := hexit.ParsePosition(request.Position)
+	if err != nil {
+		return err, hexit.Game{}
+	}
+	err, game := hexit.NewGameFromBoard(board, swapRule)
+	if request.CurrentPlayer == 0 && err != nil {
+		return err, hexit.Game{}
+	}
+	if request.CurrentPlayer != 0 {
+		if request.CurrentPlayer != 1 && request.CurrentPlayer != 2 {
+			return errors.New("Current player must be 1 or 2"), hexit.Game{}
+		}
+		if err == nil {
+			// The stones already say who is to move
+			if request.CurrentPlayer != game.CurrentPlayer {
+				return fmt.Errorf("Player %d is to move in this position", game.CurrentPlayer), hexit.Game{}
+			}
+		} else {
+			numRows, numCols := hexit.GetBoardDimensions(board)
+			if swapRule == hexit.SwapPiecesRule && numRows != numCols {
+				return errors.New("Swapping pieces requires a square board"), hexit.Game{}
+			}
+			// The request says who is to move, so the players don't need to have a usual number of stones
+			game = hexit.Game{
+				CurrentPlayer: request.CurrentPlayer,
+				MoveNum:       hexit.GetBoardMoveNum(board, swapRule),
+				SwapRule:      swapRule,
+				Board:         board,
+			}
+			// Only Player 2 can swap, and only when Player 1 has played the first stone
+			if hexit.CanSwap(game) {
+				return errors.New("Player 2 can only swap when Player 1 has the only stone"), hexit.Game{}
+			}
+		}
+	}
+
+	if request.SwitchedSides {
+		if swapRule != hexit.SwapSidesRule || game.MoveNum == 1 {
+			return errors.New("Player 2 can only switch sides after the first stone, with the swap-sides rule"), hexit.Game{}
+		}
+		game.SwitchedSides = true
+		if game.MoveNum == 2 {
+			// Switching sides was a move of its own
+			game.MoveNum = 3
+		}
+	}
+	if request.MoveNum != 0 && request.MoveNum != game.MoveNum {
+		// The only choice that the stones leave open is whether Player 2 has already decided not to swap on move 2
+		if !hexit.CanSwap(game) {
+			return fmt.Errorf("Move number must be %d for this position", game.MoveNum), hexit.Game{}
+		}
+		if request.MoveNum != 3 {
+			return errors.New("Move number must be 2 or 3 for this position"), hexit.Game{}
+		}
+		game.MoveNum = 3
+	}
+	// The search hashes the move after this one, so there has to be a move number for it
+	if game.MoveNum > hexit.MaxBoardSize*hexit.MaxBoardSize+1 {
+		return errors.New("Move number is too large"), hexit.Game{}
+	}
+	if hexit.GetGameWinner(game) != 0 {
+		return errors.New("The game is already over"), hexit.Game{}
+	}
+	game.Hash = hexit.ComputeGameHash(game)
+	return nil, game
+}
+
+// Analyze searches the position described by a request, with one of the given evaluators, on numThreads goroutines.
+// Requests can't ask for more than maxVisits visits.
+func Analyze(request Request, evaluators map[string]hexit.Evaluator, maxVisits int, numThreads int) (error, Response) {
+	err, game := NewGameFromRequest(request)
+	if err != nil {
+		return err, Response{}
+	}
+	evaluatorName := request.Evaluator
+	if evaluatorName == "" {
+		evaluatorName = DefaultEvaluator
+	}
+	evaluatePosition, ok := evaluators[evaluatorName]
+	if !ok {
+		return fmt.Errorf("Unknown evaluator %q", evaluatorName), Response{}
+	}
+	numVisits := request.Visits
+	if numVisits == 0 {
+		numVisits = DefaultVisits
+	}
+	if numVisits < 1 || numVisits > maxVisits {
+		return fmt.Errorf("Number of visits must be between 1 and %d", maxVisits), Response{}
+	}
+
+	tree := hexit.NewSearchTree(evaluatePosition, game)
+	hexit.DoParallelVisits(&tree, evaluatePosition, numVisits, numThreads)
+
+	response := Response{
+		BestMove:           hexit.FormatLocation(hexit.GetBestMove(&tree)),
+		ExpectedValue:      hexit.GetExpectedValueOfGame(&tree),
+		Moves:              make([]MoveAnalysis, 0),
+		PrincipalVariation: make([]string, 0),
+		Visits:             numVisits,
+	}
+	if hexit.CanSwap(game) && hexit.ShouldSwitchSides(&tree) {
+		response.BestMove = hexit.FormatMove(hexit.GetSwapMove(game))
+	}
+	totalVisits := uint32(0)
+	moveStats := hexit.GetRootMoveStats(&tree)
+	for _, stats := range moveStats {
+		totalVisits += stats.N
+	}
+	for _, stats := range moveStats {
+		response.Moves = append(response.Moves, MoveAnalysis{
+			Move:          hexit.FormatLocation(stats.Move),
+			N:             stats.N,
+			VisitFraction: float32(stats.N) / float32(totalVisits),
+			Q:             stats.Q,
+			P:             stats.P,
+			U:             stats.U,
+		})
+	}
+	for _, lineMove := range hexit.GetPrincipalLine(&tree) {
+		response.PrincipalVariation = append(response.PrincipalVariation, hexit.FormatMove(lineMove.Move))
+	}
+	return nil, response
+}
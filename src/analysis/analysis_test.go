@@ -0,0 +1,152 @@
+package analysis
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	hexit "github.com/uyhcire/hexit/src"
+)
+
+var testEvaluators = map[string]hexit.Evaluator{"uniform": hexit.EvaluatePositionUniformly}
+
+func TestNewGameFromRequest(t *testing.T) {
+	err, game := NewGameFromRequest(Request{Position: "x----/-o---/-----/-----/--x--"})
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.CurrentPlayer != 2 || game.MoveNum != 5 || game.SwitchedSides {
+		t.Error("Expected Player 2 to play move 5, after deciding not to swap on move 2")
+	}
+
+	err, game = NewGameFromRequest(Request{Position: "x--/---/---", SwitchedSides: true})
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.CurrentPlayer != 2 || game.MoveNum != 3 || !game.SwitchedSides || hexit.GetOriginalPlayer(game) != 1 {
+		t.Error("Expected Player 1 to play move 3 as Player 2's color after switching sides")
+	}
+
+	err, game = NewGameFromRequest(Request{Position: "x--/---/---", MoveNum: 3})
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.CurrentPlayer != 2 || game.MoveNum != 3 || hexit.CanSwap(game) || game.Hash != hexit.ComputeGameHash(game) {
+		t.Error("Expected Player 2 to play move 3, after deciding not to swap")
+	}
+
+	err, game = NewGameFromRequest(Request{Position: "x--/-x-/---", CurrentPlayer: 1})
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.CurrentPlayer != 1 || game.MoveNum != 4 || game.Hash != hexit.ComputeGameHash(game) {
+		t.Error("Expected the request's side to move")
+	}
+
+	// After Player 2 swaps pieces, their stone is the only one on the board, and Player 1 plays move 3
+	err, game = NewGameFromRequest(Request{Position: "---/o--/---", SwapRule: "swap-pieces", CurrentPlayer: 1})
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.CurrentPlayer != 1 || game.MoveNum != 3 || hexit.CanSwap(game) {
+		t.Error("Expected Player 1 to play move 3, after Player 2 swapped pieces")
+	}
+
+	for _, invalidRequest := range []Request{
+		Request{Position: "x-/y-"},
+		Request{Position: "o-/--"},
+		Request{Position: "x-/x-"},
+		Request{Position: "x-/--", SwapRule: "none", SwitchedSides: true},
+		Request{Position: "x-/--", CurrentPlayer: 3},
+		Request{Position: "x--/---/---", CurrentPlayer: 1},
+		Request{Position: "---/o--/---", SwapRule: "swap-pieces", CurrentPlayer: 2},
+		Request{Position: "---/o--/---", CurrentPlayer: 1},
+		Request{Position: "---/o--/---", CurrentPlayer: 2},
+		Request{Position: "x--/---/---", MoveNum: 4},
+		Request{Position: "x--/---/---", SwitchedSides: true, MoveNum: 2},
+		Request{Position: "x--/-o-/---", MoveNum: 3},
+		Request{Position: "x--/-o-/---", SwapRule: "none", MoveNum: 4},
+		Request{Position: "x--/-o-/---", MoveNum: -1},
+	} {
+		err, _ = NewGameFromRequest(invalidRequest)
+		if err == nil {
+			t.Errorf("Expected %+v to be invalid", invalidRequest)
+		}
+	}
+}
+
+func TestRequestAnalysis(t *testing.T) {
+	server := httptest.NewServer(NewHandler(testEvaluators, 1000, 2))
+	defer server.Close()
+
+	// Player 1 can win at a5
+	err, response := RequestAnalysis(server.URL, Request{
+		Position:  "xo---/xo---/xo---/xo---/-----",
+		Visits:    500,
+		Evaluator: "uniform",
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if response.BestMove != "a5" || response.Moves[0].Move != "a5" || response.PrincipalVariation[0] != "a5" {
+		t.Errorf("Expected a5 to be the best move, got %+v", response)
+	}
+	if response.ExpectedValue < 0.5 {
+		t.Error("Expected Player 1 to be winning")
+	}
+	if len(response.Moves) != 25-8 || response.Visits != 500 {
+		t.Error("Expected every legal move to be analyzed")
+	}
+
+	err, _ = RequestAnalysis(server.URL, Request{Position: "x----/-----/-----/-----/-----", Visits: 5000, Evaluator: "uniform"})
+	if err == nil {
+		t.Error("Expected too many visits to be rejected")
+	}
+	err, _ = RequestAnalysis(server.URL, Request{Position: "x----/-----/-----/-----/-----", Evaluator: "nn"})
+	if err == nil || err.Error() != `Unknown evaluator "nn"` {
+		t.Error("Expected an unknown evaluator to be rejected")
+	}
+}
+
+// A move number that doesn't fit the board used to make the server panic when hashing the game
+func TestAnalysisHandlerRejectsInvalidMoveNum(t *testing.T) {
+	server := httptest.NewServer(NewHandler(testEvaluators, 1000, 1))
+	defer server.Close()
+
+	for _, moveNum := range []int{100000, 362, 363, 2} {
+		err, _ := RequestAnalysis(server.URL, Request{Position: "x----/-o---/-----/-----/-----", MoveNum: moveNum, Evaluator: "uniform"})
+		if err == nil || err.Error() != "Move number must be 4 for this position" {
+			t.Errorf("Expected move number %d to be rejected, got error %v", moveNum, err)
+		}
+	}
+}
+
+func TestPrincipalVariationIncludesSwapDecision(t *testing.T) {
+	err, response := Analyze(Request{Position: "x--/---/---", Visits: 100, Evaluator: "uniform"}, testEvaluators, 1000, 1)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	decision := response.PrincipalVariation[0]
+	if decision != "swap" && decision != "noswap" {
+		t.Errorf("Expected the principal variation to start with Player 2's decision, got %v", response.PrincipalVariation)
+	}
+	if (decision == "swap") != (response.BestMove == "swap") {
+		t.Error("Expected the decision to match the best move")
+	}
+}
+
+// Player 1 used to be offered Player 2's swap after Player 2 had already swapped pieces
+func TestAnalyzeAfterSwappingPieces(t *testing.T) {
+	request := Request{Position: "---/o--/---", SwapRule: "swap-pieces", CurrentPlayer: 1, Visits: 100, Evaluator: "uniform"}
+	err, response := Analyze(request, testEvaluators, 1000, 1)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, move := range response.Moves {
+		if move.Move == "swap-pieces" || move.Move == "noswap" {
+			t.Errorf("Expected Player 1 not to be able to swap, got %v", response.Moves)
+		}
+	}
+	if response.BestMove == "swap-pieces" || len(response.Moves) != 8 {
+		t.Errorf("Expected Player 1 to play one of the 8 empty squares, got %+v", response)
+	}
+}
@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	hexit "github.com/uyhcire/hexit/src"
+)
+
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Println(err)
+	}
+}
+
+// NewHandler creates an HTTP handler that analyzes the position POSTed to it as a JSON Request, and responds with a JSON Response.
+// Requests can use any of the given evaluators, and can't ask for more than maxVisits visits.
+func NewHandler(evaluators map[string]hexit.Evaluator, maxVisits int, numThreads int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "Expected POST"})
+			return
+		}
+		var request Request
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		err, response := Analyze(request, evaluators, maxVisits, numThreads)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+}
+
+// RequestAnalysis sends a request to a server's analysis endpoint, such as "http://localhost:8080/api/analyze"
+func RequestAnalysis(url string, request Request) (error, Response) {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return err, Response{}
+	}
+	httpResponse, err := http.Post(url, "application/json", bytes.NewReader(requestBody))
+	if err != nil {
+		return err, Response{}
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		var errorBody errorResponse
+		if err := json.NewDecoder(httpResponse.Body).Decode(&errorBody); err != nil || errorBody.Error == "" {
+			return fmt.Errorf("Analysis failed with status %s", httpResponse.Status), Response{}
+		}
+		return errors.New(errorBody.Error), Response{}
+	}
+	var response Response
+	if err := json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return err, Response{}
+	}
+	return nil, response
+}
@@ -0,0 +1,149 @@
+package hexit
+
+import "testing"
+
+func TestGetRootMoveStats(t *testing.T) {
+	game := NewGame()
+	// Player 1 can win by playing at (4, 0)
+	game.Board = [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+	}
+
+	tree := NewSearchTree(EvaluatePositionUniformly, game)
+	for i := 0; i < 1000; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+
+	moveStats := GetRootMoveStats(&tree)
+	if len(moveStats) != 21 {
+		t.Errorf("Expected stats for 21 legal moves, got %d", len(moveStats))
+	}
+	if moveStats[0].Move != (Move{Row: 4, Col: 0}) || moveStats[0].Move != GetBestMove(&tree) {
+		t.Error("Expected the winning move to be first")
+	}
+	if moveStats[0].Q < 0.9 {
+		t.Error("Expected the winning move to have a high value for Player 1")
+	}
+	for i := 1; i < len(moveStats); i++ {
+		if moveStats[i].N > moveStats[i-1].N {
+			t.Error("Expected moves to be sorted by number of visits")
+		}
+	}
+	totalVisits := uint32(0)
+	for _, stats := range moveStats {
+		totalVisits += stats.N
+	}
+	if totalVisits != 1000 {
+		t.Errorf("Expected 1000 visits in total, got %d", totalVisits)
+	}
+}
+
+func TestGetPrincipalVariation(t *testing.T) {
+	game := NewGame()
+	// Player 1 can win by playing at (4, 0)
+	game.Board = [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+	}
+
+	tree := NewSearchTree(EvaluatePositionUniformly, game)
+	for i := 0; i < 1000; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+
+	// The winning move ends the game, so the line stops there
+	principalVariation := GetPrincipalVariation(&tree)
+	if len(principalVariation) != 1 || principalVariation[0] != (Move{Row: 4, Col: 0}) {
+		t.Errorf("Expected the principal variation to be the winning move, got %v", principalVariation)
+	}
+
+	tree = NewSearchTree(EvaluatePositionUniformly, NewGame())
+	if len(GetPrincipalVariation(&tree)) != 0 {
+		t.Error("Expected no principal variation before searching")
+	}
+	for i := 0; i < 1000; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+	principalVariation = GetPrincipalVariation(&tree)
+	if len(principalVariation) < 2 || principalVariation[0] != GetBestMove(&tree) {
+		t.Error("Expected the principal variation to start with the best move")
+	}
+}
+
+func TestGetPrincipalLine(t *testing.T) {
+	tree := NewSearchTree(EvaluatePositionUniformly, NewGameWithBoardSize(3))
+	for i := 0; i < 1000; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+
+	// Player 2's decision comes after Player 1's first stone
+	principalLine := GetPrincipalLine(&tree)
+	principalVariation := GetPrincipalVariation(&tree)
+	if len(principalLine) != len(principalVariation)+1 {
+		t.Fatal("Expected the principal line to have one more move than the principal variation")
+	}
+	if principalLine[0].Move != (GameMove{Type: RegularMove, Location: principalVariation[0]}) {
+		t.Error("Expected the principal line to start with the best move")
+	}
+	decision := principalLine[1].Move.Type
+	if decision != SwitchSidesMove && decision != DoNotSwitchSidesMove {
+		t.Error("Expected Player 2 to decide whether to switch sides on move 2")
+	}
+	if principalLine[1].N != principalLine[0].N || principalLine[1].Q < 0 {
+		t.Error("Expected Player 2 to make the decision that's better for them")
+	}
+	for i := 2; i < len(principalLine); i++ {
+		if principalLine[i].Move != (GameMove{Type: RegularMove, Location: principalVariation[i-1]}) {
+			t.Error("Expected the rest of the principal line to follow the principal variation")
+		}
+	}
+}
+
+func TestGetTopLines(t *testing.T) {
+	err, game := PlayGameMove(NewGameWithRules(3, 3, SwapPiecesRule), 1, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	tree := NewSearchTree(EvaluatePositionUniformly, game)
+	for i := 0; i < 1000; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+
+	// Pretend that the center is bad for Player 1, so Player 2 doesn't swap
+	tree.rootNode.q = -0.5
+	lines := GetTopLines(&tree, 3)
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	moveStats := GetRootMoveStats(&tree)
+	for i, line := range lines {
+		if line[0].Move.Type != DoNotSwitchSidesMove || line[0].Q != 0.5 {
+			t.Error("Expected every line to start with Player 2 deciding not to swap")
+		}
+		if line[1].Move.Location != moveStats[i].Move || line[1].N != moveStats[i].N {
+			t.Error("Expected the lines to follow the most visited moves")
+		}
+	}
+
+	// Swapping pieces changes the position, so the search has nothing more to say
+	tree.rootNode.q = 0.5
+	lines = GetTopLines(&tree, 3)
+	if len(lines) != 1 || len(lines[0]) != 1 || lines[0][0].Move.Type != SwapPiecesMove {
+		t.Error("Expected a single line where Player 2 swaps pieces")
+	}
+	if len(GetPrincipalLine(&tree)) != 1 {
+		t.Error("Expected the principal line to be the swap")
+	}
+	for _, k := range []int{0, -1} {
+		if lines = GetTopLines(&tree, k); lines == nil || len(lines) != 0 {
+			t.Errorf("Expected no lines for k = %d", k)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package hexit
+
+import "fmt"
+
+// BatchEvaluator is like Evaluator, but evaluates several positions at once.
+// It returns a value estimate and policy estimates for each board, in the same order as the boards.
+type BatchEvaluator = func([]Board, []byte) ([]float32, [][][]float32)
+
+// NewBatchEvaluator makes a BatchEvaluator that evaluates the positions of a batch one at a time
+func NewBatchEvaluator(evaluatePosition Evaluator) BatchEvaluator {
+	return func(boards []Board, players []byte) ([]float32, [][][]float32) {
+		valueEstimates := make([]float32, len(boards))
+		policyEstimates := make([][][]float32, len(boards))
+		for i, board := range boards {
+			valueEstimates[i], policyEstimates[i] = evaluatePosition(board, players[i])
+		}
+		return valueEstimates, policyEstimates
+	}
+}
+
+// GetBatchEvaluator gets a batch evaluator by name, like GetEvaluator.
+// Only "nn" evaluates a whole batch at once, in a single tensor.
+func GetBatchEvaluator(name string) (error, BatchEvaluator) {
+	switch name {
+	case "random":
+		return nil, NewBatchEvaluator(EvaluatePositionRandomly)
+	case "uniform":
+		return nil, NewBatchEvaluator(EvaluatePositionUniformly)
+	case "nn":
+		InitializeModel()
+		return nil, EvaluatePositionsWithNN
+	}
+	return fmt.Errorf("Unknown evaluator %q", name), nil
+}
+
+// DoBatchedVisits performs numVisits iterations of tree search, evaluating up to batchSize leaves at once.
+// It selects leaves one after another, with virtual loss on the paths to the leaves it has already selected,
+// and evaluates them together when the batch is full or when it selects a leaf that's already in the batch.
+func DoBatchedVisits(tree *SearchTree, evaluatePositions BatchEvaluator, numVisits int, batchSize int) {
+	numVisitsLeft := numVisits
+	for numVisitsLeft > 0 {
+		leafNodes := make([]*SearchNode, 0, batchSize)
+		leafBoards := make([]Board, 0, batchSize)
+		leafPlayers := make([]byte, 0, batchSize)
+		isInBatch := make(map[*SearchNode]bool)
+		for numVisitsLeft > 0 && len(leafNodes) < batchSize {
+			leafNode, leafGame, leafPosition := selectLeaf(tree)
+			if GetPositionWinner(&leafPosition) != 0 {
+				// There's nothing to evaluate, so the visit can be finished right away
+				leafNode.isTerminal = true
+				leafNode.v = 1
+				backUpValue(tree, leafNode, leafNode.v)
+				numVisitsLeft--
+				continue
+			}
+			if isInBatch[leafNode] {
+				// The search keeps coming back to the same leaf, so evaluate the batch before going on
+				break
+			}
+			numVisitsLeft--
+			leafBoard := GetPositionBoard(&leafPosition)
+			if expandFromTransposition(leafNode, leafBoard) {
+				backUpValue(tree, leafNode, leafNode.v)
+				continue
+			}
+			isInBatch[leafNode] = true
+			addVirtualLoss(tree, leafNode, 1)
+			leafNodes = append(leafNodes, leafNode)
+			leafBoards = append(leafBoards, leafBoard)
+			leafPlayers = append(leafPlayers, leafGame.CurrentPlayer)
+		}
+		if len(leafNodes) == 0 {
+			continue
+		}
+
+		valueEstimates, policyEstimates := evaluatePositions(leafBoards, leafPlayers)
+		for i, leafNode := range leafNodes {
+			addVirtualLoss(tree, leafNode, -1)
+			storeTranspositionEvaluation(leafNode, valueEstimates[i], policyEstimates[i])
+			expandNode(leafNode, leafBoards[i], valueEstimates[i], policyEstimates[i])
+			backUpValue(tree, leafNode, leafNode.v)
+		}
+	}
+}
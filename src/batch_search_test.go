@@ -0,0 +1,55 @@
+package hexit
+
+import "testing"
+
+func TestDoBatchedVisits(t *testing.T) {
+	game := NewGame()
+	// Player 1 can win by playing at (4, 0)
+	game.Board = [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+	}
+
+	batchSizes := make([]int, 0)
+	evaluateUniformly := NewBatchEvaluator(EvaluatePositionUniformly)
+	evaluatePositions := func(boards []Board, players []byte) ([]float32, [][][]float32) {
+		batchSizes = append(batchSizes, len(boards))
+		return evaluateUniformly(boards, players)
+	}
+
+	tree := NewSearchTree(EvaluatePositionUniformly, game)
+	DoBatchedVisits(&tree, evaluatePositions, 1000, 8)
+	if tree.rootNode.n != 1000 {
+		t.Errorf("Expected 1000 visits, got %d", tree.rootNode.n)
+	}
+	checkVisitCounts(t, tree.rootNode, true)
+
+	maxBatchSize := 0
+	for _, batchSize := range batchSizes {
+		if batchSize > maxBatchSize {
+			maxBatchSize = batchSize
+		}
+	}
+	if maxBatchSize != 8 {
+		t.Errorf("Expected some batches of 8 positions, but the largest batch had %d", maxBatchSize)
+	}
+
+	bestMove := GetBestMove(&tree)
+	if bestMove.Row != 4 || bestMove.Col != 0 {
+		t.Error("Failed to find the winning move")
+	}
+}
+
+func TestNewBatchEvaluator(t *testing.T) {
+	boards := []Board{NewBoard(3), NewRectangularBoard(2, 4)}
+	valueEstimates, policyEstimates := NewBatchEvaluator(EvaluatePositionUniformly)(boards, []byte{1, 2})
+	if len(valueEstimates) != 2 || len(policyEstimates) != 2 {
+		t.Fatal("Expected an evaluation for each board")
+	}
+	if len(policyEstimates[1]) != 2 || len(policyEstimates[1][0]) != 4 {
+		t.Error("Expected the policy estimates to have the same dimensions as the board")
+	}
+}
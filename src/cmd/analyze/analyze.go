@@ -0,0 +1,187 @@
+package main
+
+// Searches a position and prints what the engine thinks of it, for example:
+//
+//	go run src/cmd/analyze/analyze.go -sgf game.sgf -move 12 -evaluator nn -visits 5000
+//	go run src/cmd/analyze/analyze.go -position x----/-o---/-----/-----/-----
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	hexit "github.com/uyhcire/hexit/src"
+)
+
+// getLineAfterMove finds the line of play that starts with one of the root's moves.
+// It leaves out Player 2's decision on move 2 at the start of the line, since printAnalysis prints the decision above the table.
+func getLineAfterMove(lines [][]hexit.LineMove, move hexit.Move) []hexit.LineMove {
+	for _, line := range lines {
+		for i, lineMove := range line {
+			if lineMove.Move.Type == hexit.RegularMove {
+				if lineMove.Move.Location == move {
+					return line[i:]
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func formatLine(line []hexit.LineMove) string {
+	formattedMoves := make([]string, len(line))
+	for i, lineMove := range line {
+		formattedMoves[i] = hexit.FormatMove(lineMove.Move)
+	}
+	return strings.Join(formattedMoves, " ")
+}
+
+// printAnalysis prints the root value, and a table of the most visited moves with their lines of play
+func printAnalysis(tree *hexit.SearchTree, game hexit.Game, numMovesToShow int) {
+	fmt.Printf("%s to move (move %d)\n", hexit.GetPlayerSymbol(game.CurrentPlayer), game.MoveNum)
+	fmt.Printf("Root value: %+.3f (+1 means X wins, -1 means O wins)\n", hexit.GetExpectedValueOfGame(tree))
+	if hexit.CanSwap(game) {
+		if hexit.ShouldSwitchSides(tree) {
+			fmt.Println("O should swap")
+		} else {
+			fmt.Println("O should not swap, and should play one of these moves")
+		}
+	}
+	fmt.Println()
+
+	fmt.Printf("%-4s  %6s  %6s  %6s  %6s  %s\n", "Move", "N", "Q", "P", "U", "Line")
+	moveStats := hexit.GetRootMoveStats(tree)
+	// If O should swap pieces, the moves have no lines, because the search didn't look at the position after the swap
+	lines := hexit.GetTopLines(tree, numMovesToShow)
+	for i := 0; i < len(moveStats) && i < numMovesToShow; i++ {
+		stats := moveStats[i]
+		fmt.Printf(
+			"%-4s  %6d  %+6.3f  %6.3f  %6.3f  %s\n",
+			hexit.FormatLocation(stats.Move),
+			stats.N,
+			stats.Q,
+			stats.P,
+			stats.U,
+			formatLine(getLineAfterMove(lines, stats.Move)),
+		)
+	}
+}
+
+func main() {
+	position := flag.String("position", "", "position to analyze, row by row from the top, such as x----/-o---/-----/-----/-----")
+	sgfFilename := flag.String("sgf", "", "game record to analyze")
+	numMoves := flag.Int("move", -1, "with -sgf, analyze the position after this many moves instead of at the end of the game")
+	evaluatorName := flag.String("evaluator", "random", "position evaluator: random, uniform or nn")
+	modelPath := flag.String("model", hexit.DefaultModelPath, "saved model folder for the nn evaluator")
+	numVisits := flag.Int("visits", 1000, "number of search visits")
+	numThreads := flag.Int("threads", 1, "number of goroutines that search at once")
+	batchSize := flag.Int("batch", 1, "number of positions to evaluate at once, which speeds up the nn evaluator")
+	numTrees := flag.Int("trees", 1, "number of independent search trees to search at once and merge, which only keeps the moves from the root")
+	useTranspositions := flag.Bool("transpositions", false, "evaluate each position only once, even if it's reached by different move orders")
+	shareStats := flag.Bool("share-stats", false, "with -transpositions, also share visits and values between move orders that reach the same position")
+	numMovesToShow := flag.Int("top", 10, "number of moves to show")
+	swapRuleName := flag.String("swap", hexit.FormatSwapRule(hexit.SwapSidesRule), "swap rule for -position: swap-sides, swap-pieces or none")
+	dotFilename := flag.String("dot", "", "file to write the search tree to, as a Graphviz graph")
+	jsonFilename := flag.String("json", "", "file to write the search tree to, as JSON")
+	maxDepth := flag.Int("depth", 3, "with -dot or -json, how many moves deep to write the search tree, or 0 for the whole tree")
+	minVisits := flag.Uint("min-visits", 10, "with -dot or -json, leave out moves with fewer visits")
+	flag.Parse()
+	err, swapRule := hexit.ParseSwapRule(*swapRuleName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	err, game := hexit.LoadGame(*position, *sgfFilename, *numMoves, swapRule)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if hexit.GetGameWinner(game) != 0 {
+		fmt.Fprintln(os.Stderr, "The game is already over")
+		os.Exit(2)
+	}
+	if *numVisits < 1 {
+		fmt.Fprintln(os.Stderr, "Number of visits must be at least 1")
+		os.Exit(2)
+	}
+	if *numThreads < 1 {
+		fmt.Fprintln(os.Stderr, "Number of threads must be at least 1")
+		os.Exit(2)
+	}
+	if *batchSize < 1 {
+		fmt.Fprintln(os.Stderr, "Batch size must be at least 1")
+		os.Exit(2)
+	}
+	if *numTrees < 1 {
+		fmt.Fprintln(os.Stderr, "Number of trees must be at least 1")
+		os.Exit(2)
+	}
+	numSearchModes := 0
+	for _, setting := range []int{*numThreads, *batchSize, *numTrees} {
+		if setting > 1 {
+			numSearchModes++
+		}
+	}
+	if numSearchModes > 1 {
+		fmt.Fprintln(os.Stderr, "Use only one of -threads, -batch and -trees")
+		os.Exit(2)
+	}
+	if *useTranspositions && *numTrees > 1 {
+		fmt.Fprintln(os.Stderr, "-transpositions can't be used with -trees")
+		os.Exit(2)
+	}
+	if *shareStats && !*useTranspositions {
+		fmt.Fprintln(os.Stderr, "-share-stats requires -transpositions")
+		os.Exit(2)
+	}
+	if *evaluatorName == "nn" {
+		hexit.InitializeModelFromPath(*modelPath)
+	}
+	err, evaluatePosition := hexit.GetEvaluator(*evaluatorName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	_, evaluatePositions := hexit.GetBatchEvaluator(*evaluatorName)
+
+	options := hexit.RenderOptions{Labels: true}
+	if lastMove, ok := hexit.GetLastStoneLocation(game); ok {
+		options.LastMove = &lastMove
+	}
+	fmt.Print(hexit.RenderBoard(game.Board, options))
+
+	var tree hexit.SearchTree
+	if *numTrees > 1 {
+		tree = hexit.DoRootParallelSearch(evaluatePosition, game, *numVisits, *numTrees)
+	} else {
+		tree = hexit.NewSearchTree(evaluatePosition, game)
+		if *useTranspositions {
+			hexit.EnableTranspositions(&tree, *shareStats)
+		}
+		if *batchSize > 1 {
+			hexit.DoBatchedVisits(&tree, evaluatePositions, *numVisits, *batchSize)
+		} else {
+			hexit.DoParallelVisits(&tree, evaluatePosition, *numVisits, *numThreads)
+		}
+	}
+	printAnalysis(&tree, game, *numMovesToShow)
+
+	exportOptions := hexit.TreeExportOptions{MaxDepth: *maxDepth, MinVisits: uint32(*minVisits)}
+	if *dotFilename != "" {
+		err = ioutil.WriteFile(*dotFilename, []byte(hexit.ExportTreeDOT(&tree, exportOptions)), 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *jsonFilename != "" {
+		err = ioutil.WriteFile(*jsonFilename, []byte(hexit.ExportTreeJSON(&tree, exportOptions)), 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+// Renders a position or a game record as SVG and PNG diagrams, for example:
+//
+//	go run src/cmd/diagram/diagram.go -sgf game.sgf -move 12 -png move12.png
+//	go run src/cmd/diagram/diagram.go -position x----/-o---/-----/-----/----- -heatmap visits -svg position.svg
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image/png"
+	"io/ioutil"
+	"os"
+
+	hexit "github.com/uyhcire/hexit/src"
+)
+
+// getHeatmap searches the position and arranges the root's visit counts or policy by square
+func getHeatmap(game hexit.Game, heatmapName string, evaluatePosition hexit.Evaluator, numVisits int, numThreads int) (error, [][]float32) {
+	if heatmapName != "visits" && heatmapName != "policy" {
+		return fmt.Errorf("Unknown heatmap %q", heatmapName), nil
+	}
+	if hexit.GetGameWinner(game) != 0 {
+		return errors.New("Can't draw a heatmap after the game is over"), nil
+	}
+	tree := hexit.NewSearchTree(evaluatePosition, game)
+	hexit.DoParallelVisits(&tree, evaluatePosition, numVisits, numThreads)
+
+	numRows, numCols := hexit.GetBoardDimensions(game.Board)
+	heatmap := make([][]float32, numRows)
+	for i := range heatmap {
+		heatmap[i] = make([]float32, numCols)
+	}
+	for _, stats := range hexit.GetRootMoveStats(&tree) {
+		if heatmapName == "visits" {
+			heatmap[stats.Move.Row][stats.Move.Col] = float32(stats.N)
+		} else {
+			heatmap[stats.Move.Row][stats.Move.Col] = stats.P
+		}
+	}
+	return nil, heatmap
+}
+
+func writePNG(filename string, board hexit.Board, options hexit.DiagramOptions) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(file, hexit.RenderBoardPNG(board, options))
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func main() {
+	position := flag.String("position", "", "position to draw, row by row from the top, such as x----/-o---/-----/-----/-----")
+	sgfFilename := flag.String("sgf", "", "game record to draw")
+	numMoves := flag.Int("move", -1, "with -sgf, draw the position after this many moves instead of at the end of the game")
+	svgFilename := flag.String("svg", "", "file to write an SVG diagram to")
+	pngFilename := flag.String("png", "", "file to write a PNG diagram to")
+	showLabels := flag.Bool("labels", true, "show coordinates around the board")
+	showMoveNums := flag.Bool("numbers", true, "with -sgf, number the stones in the order they were played")
+	showLastMove := flag.Bool("last", true, "with -sgf, outline the last stone that was played")
+	heatmapName := flag.String("heatmap", "", "shade empty squares by the visits or policy of a search: visits or policy")
+	evaluatorName := flag.String("evaluator", "random", "position evaluator for -heatmap: random, uniform or nn")
+	modelPath := flag.String("model", hexit.DefaultModelPath, "saved model folder for the nn evaluator")
+	numVisits := flag.Int("visits", 1000, "number of search visits for -heatmap")
+	numThreads := flag.Int("threads", 1, "number of goroutines that search at once for -heatmap")
+	swapRuleName := flag.String("swap", hexit.FormatSwapRule(hexit.SwapSidesRule), "swap rule for -position: swap-sides, swap-pieces or none")
+	flag.Parse()
+	if *svgFilename == "" && *pngFilename == "" {
+		fmt.Fprintln(os.Stderr, "Expected -svg or -png")
+		os.Exit(2)
+	}
+	err, swapRule := hexit.ParseSwapRule(*swapRuleName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	err, game := hexit.LoadGame(*position, *sgfFilename, *numMoves, swapRule)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	options := hexit.DiagramOptions{Labels: *showLabels}
+	if *showMoveNums {
+		options.MoveNums = hexit.GetStoneMoveNums(game)
+	}
+	if *showLastMove {
+		if lastMove, ok := hexit.GetLastStoneLocation(game); ok {
+			options.LastMove = &lastMove
+		}
+	}
+	if *heatmapName != "" {
+		if *numVisits < 1 {
+			fmt.Fprintln(os.Stderr, "Number of visits must be at least 1")
+			os.Exit(2)
+		}
+		if *numThreads < 1 {
+			fmt.Fprintln(os.Stderr, "Number of threads must be at least 1")
+			os.Exit(2)
+		}
+		if *evaluatorName == "nn" {
+			hexit.InitializeModelFromPath(*modelPath)
+		}
+		err, evaluatePosition := hexit.GetEvaluator(*evaluatorName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		err, options.Heatmap = getHeatmap(game, *heatmapName, evaluatePosition, *numVisits, *numThreads)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	if *svgFilename != "" {
+		err = ioutil.WriteFile(*svgFilename, []byte(hexit.RenderBoardSVG(game.Board, options)), 0644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *pngFilename != "" {
+		err = writePNG(*pngFilename, game.Board, options)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+// An engine that speaks the Hex Text Protocol (HTP), which is used by HexGui and Hex tournament tools.
+// HTP is based on the Go Text Protocol: https://www.lysator.liu.se/~gunnar/gtp/
+//
+// Black is Player 1, who connects the top and bottom, and White is Player 2, who connects the left and right.
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	hexit "github.com/uyhcire/hexit/src"
+)
+
+const engineName = "hexit"
+const engineVersion = "0.1"
+
+// HexGui writes switching sides as "swap-sides", and hexit's notation writes it as "swap"
+const swapSidesToken = "swap-sides"
+
+var commandNames = []string{
+	"boardsize",
+	"clear_board",
+	"genmove",
+	"known_command",
+	"list_commands",
+	"name",
+	"play",
+	"protocol_version",
+	"quit",
+	"showboard",
+	"undo",
+	"version",
+}
+
+// engine is the state of an HTP session
+type engine struct {
+	game             hexit.Game
+	swapRule         hexit.SwapRule
+	evaluatePosition hexit.Evaluator
+	numVisits        int
+	numThreads       int
+}
+
+// parseColor parses a color such as "b" or "white" into the player who plays that color
+func parseColor(text string) (error, byte) {
+	switch strings.ToLower(text) {
+	case "b", "black":
+		return nil, 1
+	case "w", "white":
+		return nil, 2
+	}
+	return fmt.Errorf("Invalid color %q", text), 0
+}
+
+func formatColor(player byte) string {
+	if player == 1 {
+		return "black"
+	}
+	return "white"
+}
+
+// checkTurn checks that it's the given color's turn in an unfinished game
+func checkTurn(game hexit.Game, player byte) error {
+	if hexit.GetGameWinner(game) != 0 {
+		return errors.New("Game is over")
+	}
+	if player != game.CurrentPlayer {
+		return fmt.Errorf("It's %s's turn", formatColor(game.CurrentPlayer))
+	}
+	return nil
+}
+
+func playCommand(e *engine, args []string) (error, string) {
+	if len(args) != 2 {
+		return errors.New("Expected a color and a move"), ""
+	}
+	err, player := parseColor(args[0])
+	if err != nil {
+		return err, ""
+	}
+	err = checkTurn(e.game, player)
+	if err != nil {
+		return err, ""
+	}
+
+	numRows, numCols := hexit.GetBoardDimensions(e.game.Board)
+	err, move := hexit.ParseMove(args[1], numRows, numCols)
+	if err != nil {
+		return err, ""
+	}
+	if move.Type == hexit.DoNotSwitchSidesMove {
+		return fmt.Errorf("Invalid move %q", args[1]), ""
+	}
+
+	err, e.game = hexit.ApplyGameMove(e.game, move)
+	return err, ""
+}
+
+func genmoveCommand(e *engine, args []string) (error, string) {
+	if len(args) != 1 {
+		return errors.New("Expected a color"), ""
+	}
+	err, player := parseColor(args[0])
+	if err != nil {
+		return err, ""
+	}
+	err = checkTurn(e.game, player)
+	if err != nil {
+		return err, ""
+	}
+
+	tree := hexit.NewSearchTree(e.evaluatePosition, e.game)
+	hexit.DoParallelVisits(&tree, e.evaluatePosition, e.numVisits, e.numThreads)
+
+	if hexit.CanSwap(e.game) && hexit.ShouldSwitchSides(&tree) {
+		err, e.game = hexit.Swap(e.game)
+		if err != nil {
+			panic(err)
+		}
+		if e.game.SwitchedSides {
+			return nil, swapSidesToken
+		}
+		return nil, hexit.FormatMove(hexit.GameMove{Type: hexit.SwapPiecesMove})
+	}
+
+	move := hexit.GameMove{Type: hexit.RegularMove, Location: hexit.GetBestMove(&tree)}
+	err, e.game = hexit.ApplyGameMove(e.game, move)
+	if err != nil {
+		panic(err)
+	}
+	return nil, hexit.FormatMove(move)
+}
+
+func undoCommand(e *engine, args []string) (error, string) {
+	err, game := hexit.Undo(e.game)
+	if err != nil {
+		return err, ""
+	}
+	// Not swapping is implied by Player 2's first stone, so undo both
+	playedMoves := hexit.GetPlayedMoves(game)
+	if len(playedMoves) > 0 && playedMoves[len(playedMoves)-1].Type == hexit.DoNotSwitchSidesMove {
+		err, game = hexit.Undo(game)
+		if err != nil {
+			return err, ""
+		}
+	}
+	e.game = game
+	return nil, ""
+}
+
+// boardsizeCommand accepts "boardsize 7", or "boardsize 5 4" for 5 columns and 4 rows
+func boardsizeCommand(e *engine, args []string) (error, string) {
+	var boardSize string
+	switch len(args) {
+	case 1:
+		boardSize = args[0]
+	case 2:
+		boardSize = args[1] + "x" + args[0]
+	default:
+		return errors.New("Expected a board size"), ""
+	}
+	err, numRows, numCols := hexit.ParseBoardDimensions(boardSize)
+	if err != nil {
+		return err, ""
+	}
+	if e.swapRule == hexit.SwapPiecesRule && numRows != numCols {
+		return errors.New("Swapping pieces requires a square board"), ""
+	}
+	e.game = hexit.NewGameWithRules(numRows, numCols, e.swapRule)
+	return nil, ""
+}
+
+func showboardCommand(e *engine, args []string) (error, string) {
+	options := hexit.RenderOptions{Labels: true, WinningPath: true}
+	if lastMove, ok := hexit.GetLastStoneLocation(e.game); ok {
+		options.LastMove = &lastMove
+	}
+	return nil, "\n" + strings.TrimSuffix(hexit.RenderBoard(e.game.Board, options), "\n")
+}
+
+func isKnownCommand(commandName string) bool {
+	for _, knownCommandName := range commandNames {
+		if commandName == knownCommandName {
+			return true
+		}
+	}
+	return false
+}
+
+// runCommand runs a command and returns its response
+func runCommand(e *engine, commandName string, args []string) (error, string) {
+	switch commandName {
+	case "boardsize":
+		return boardsizeCommand(e, args)
+	case "clear_board":
+		numRows, numCols := hexit.GetBoardDimensions(e.game.Board)
+		e.game = hexit.NewGameWithRules(numRows, numCols, e.swapRule)
+		return nil, ""
+	case "genmove":
+		return genmoveCommand(e, args)
+	case "known_command":
+		if len(args) != 1 {
+			return errors.New("Expected a command name"), ""
+		}
+		return nil, strconv.FormatBool(isKnownCommand(args[0]))
+	case "list_commands":
+		return nil, strings.Join(commandNames, "\n")
+	case "name":
+		return nil, engineName
+	case "play":
+		return playCommand(e, args)
+	case "protocol_version":
+		return nil, "2"
+	case "quit":
+		return nil, ""
+	case "showboard":
+		return showboardCommand(e, args)
+	case "undo":
+		return undoCommand(e, args)
+	case "version":
+		return nil, engineVersion
+	}
+	return errors.New("Unknown command"), ""
+}
+
+// runSession reads commands until "quit" or the end of the input
+func runSession(e *engine, input io.Reader, output io.Writer) {
+	scanner := bufio.NewScanner(input)
+	writer := bufio.NewWriter(output)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if commentIndex := strings.Index(line, "#"); commentIndex != -1 {
+			line = line[:commentIndex]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Commands can start with an ID, which is repeated in the response
+		id := ""
+		if _, err := strconv.ParseUint(fields[0], 10, 32); err == nil {
+			id = fields[0]
+			fields = fields[1:]
+			if len(fields) == 0 {
+				continue
+			}
+		}
+
+		commandName := strings.ToLower(fields[0])
+		err, response := runCommand(e, commandName, fields[1:])
+		if err != nil {
+			fmt.Fprintf(writer, "?%s %s\n\n", id, err.Error())
+		} else {
+			fmt.Fprintf(writer, "=%s %s\n\n", id, response)
+		}
+		writer.Flush()
+
+		if commandName == "quit" {
+			return
+		}
+	}
+}
+
+func main() {
+	boardSize := flag.String("size", fmt.Sprint(hexit.DefaultBoardSize), "board size, such as 7 for a 7x7 board or 4x5 for 4 rows and 5 columns")
+	evaluatorName := flag.String("evaluator", "random", "position evaluator: random, uniform or nn")
+	numVisits := flag.Int("visits", 1000, "number of search visits per move")
+	numThreads := flag.Int("threads", 1, "number of goroutines that search at once")
+	swapRuleName := flag.String("swap", hexit.FormatSwapRule(hexit.SwapSidesRule), "swap rule: swap-sides, swap-pieces or none")
+	flag.Parse()
+	err, numRows, numCols := hexit.ParseBoardDimensions(*boardSize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	err, swapRule := hexit.ParseSwapRule(*swapRuleName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if swapRule == hexit.SwapPiecesRule && numRows != numCols {
+		fmt.Fprintln(os.Stderr, "Swapping pieces requires a square board")
+		os.Exit(2)
+	}
+	err, evaluatePosition := hexit.GetEvaluator(*evaluatorName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *numVisits < 1 {
+		fmt.Fprintln(os.Stderr, "Number of visits must be at least 1")
+		os.Exit(2)
+	}
+	if *numThreads < 1 {
+		fmt.Fprintln(os.Stderr, "Number of threads must be at least 1")
+		os.Exit(2)
+	}
+
+	e := engine{
+		game:             hexit.NewGameWithRules(numRows, numCols, swapRule),
+		swapRule:         swapRule,
+		evaluatePosition: evaluatePosition,
+		numVisits:        *numVisits,
+		numThreads:       *numThreads,
+	}
+	runSession(&e, os.Stdin, os.Stdout)
+}
@@ -1,55 +1,311 @@
 package main
 
 import (
+	"bufio"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	hexit "github.com/uyhcire/hexit/src"
 )
 
-func getHumanMove(board hexit.Board) (error, hexit.Move) {
-	hexit.PrintBoard(&board)
-	row := uint(0)
-	col := uint(0)
-	_, err := fmt.Scanf("%d,%d", &row, &col)
-	if err != nil || row < 0 || row >= 5 || col < 0 || col >= 5 || board[row][col] != 0 {
-		return errors.New("Invalid move"), hexit.Move{Row: 0, Col: 0}
+// Number of candidate moves that "hint" shows
+const numHintMoves = 5
+
+var commandHelp = []string{
+	"c3      play a move: the column letter, then the row number",
+	"swap    take over the AI's first stone (only on move 2)",
+	"hint    show the moves the AI would consider in your place",
+	"eval    show who the AI thinks is winning",
+	"undo    take back your last move and the AI's reply",
+	"help    show this list",
+	"quit    leave the game",
+}
+
+// session is the state of a game between the human and the AI
+type session struct {
+	game             hexit.Game
+	human            byte
+	evaluatePosition hexit.Evaluator
+	numVisits        int
+	numThreads       int
+	useColor         bool
+	// The AI's search tree, which is kept from move to move. It's nil until the AI searches.
+	tree *hexit.SearchTree
+}
+
+// renderGame draws the board with coordinates, marking the last move and the winning chain
+func renderGame(game hexit.Game, useColor bool) string {
+	options := hexit.RenderOptions{Color: useColor, Labels: true, WinningPath: true}
+	if lastMove, ok := hexit.GetLastStoneLocation(game); ok {
+		options.LastMove = &lastMove
 	}
-	return nil, hexit.Move{Row: row, Col: col}
+	return hexit.RenderBoard(game.Board, options)
 }
 
-func main() {
+// getHumanColor gets the color that the human plays, which changes if the AI switches sides
+func getHumanColor(s *session) byte {
+	if s.game.SwitchedSides {
+		return hexit.OtherPlayer(s.human)
+	}
+	return s.human
+}
+
+// search runs the AI's search from the current position, adding to what was searched before
+func search(s *session) *hexit.SearchTree {
+	if s.tree == nil {
+		tree := hexit.NewSearchTree(s.evaluatePosition, s.game)
+		s.tree = &tree
+	}
+	hexit.DoParallelVisits(s.tree, s.evaluatePosition, s.numVisits, s.numThreads)
+	return s.tree
+}
+
+// playMove plays a move, and advances the AI's search tree to the new position
+func playMove(s *session, move hexit.GameMove) error {
+	err, game := hexit.ApplyGameMove(s.game, move)
+	if err != nil {
+		return err
+	}
+	s.game = game
+	if s.tree != nil {
+		return hexit.AdvanceSearchTree(s.tree, s.evaluatePosition, move)
+	}
+	return nil
+}
+
+// parseHumanMove parses a move such as "c3", or "swap" on move 2, and explains what's wrong with it if it can't be played
+func parseHumanMove(text string, game hexit.Game) (error, hexit.GameMove) {
+	numRows, numCols := hexit.GetBoardDimensions(game.Board)
+	err, move := hexit.ParseMove(text, numRows, numCols)
+	if err != nil {
+		if !strings.ContainsAny(text, "0123456789") {
+			return fmt.Errorf("Unknown command %q. Type \"help\" for a list of commands", text), hexit.GameMove{}
+		}
+		return err, hexit.GameMove{}
+	}
+
+	if move.Type != hexit.RegularMove {
+		if game.SwapRule == hexit.NoSwapRule {
+			return errors.New("This game is played without the swap rule"), hexit.GameMove{}
+		}
+		if !hexit.CanSwap(game) {
+			return errors.New("You can only swap on move 2, in reply to the AI's first stone"), hexit.GameMove{}
+		}
+		if move.Type == hexit.SwitchSidesMove || move.Type == hexit.SwapPiecesMove {
+			// Swap with whichever variant the game uses
+			move = hexit.GetSwapMove(game)
+		}
+		return nil, move
+	}
+
+	if game.Board[move.Location.Row][move.Location.Col] != 0 {
+		return fmt.Errorf("%s is already occupied", hexit.FormatLocation(move.Location)), hexit.GameMove{}
+	}
+	return nil, move
+}
+
+// getEngineMove searches for the engine's move. On move 2, it also decides whether to swap.
+func getEngineMove(s *session) hexit.GameMove {
+	tree := search(s)
+	if hexit.CanSwap(s.game) && hexit.ShouldSwitchSides(tree) {
+		return hexit.GetSwapMove(s.game)
+	}
+	return hexit.GameMove{Type: hexit.RegularMove, Location: hexit.GetBestMove(tree)}
+}
+
+// printHint shows the moves that the search likes best for the human
+func printHint(s *session) {
+	tree := search(s)
+	if hexit.CanSwap(s.game) {
+		if hexit.ShouldSwitchSides(tree) {
+			fmt.Println("The AI would swap")
+		} else {
+			fmt.Println("The AI would not swap, and would play one of these moves instead")
+		}
+	}
+	moveStats := hexit.GetRootMoveStats(tree)
+	for i := 0; i < len(moveStats) && i < numHintMoves; i++ {
+		stats := moveStats[i]
+		fmt.Printf("%s (N: %d) (Q: %.2f) (P: %.2f)\n", hexit.FormatLocation(stats.Move), stats.N, stats.Q, stats.P)
+	}
+}
+
+// printEval shows the expected value of the game for the human
+func printEval(s *session) {
+	tree := search(s)
+	value := hexit.GetExpectedValueOfGame(tree)
+	if getHumanColor(s) == 2 {
+		// The expected value is from Player 1's point of view
+		value = -value
+	}
+	fmt.Printf("Expected value: %+.2f (+1 means you win, -1 means the AI wins)\n", value)
+}
+
+// undoHumanMove takes back moves until one of the human's moves has been taken back, so it's the human's turn again
+func undoHumanMove(game hexit.Game, human byte) (error, hexit.Game) {
 	var err error
-	game := hexit.NewGame()
-	for hexit.GetWinner(game.Board) == 0 {
-		if game.MoveNum == 2 {
-			err, game = hexit.DoNotSwitchSides(game)
+	previousGame := game
+	for {
+		err, previousGame = hexit.Undo(previousGame)
+		if err != nil {
+			return errors.New("There are no moves of yours to undo"), game
+		}
+		if hexit.GetOriginalPlayer(previousGame) == human {
+			break
+		}
+	}
+	// Not swapping is implied by Player 2's first stone, so undo both
+	playedMoves := hexit.GetPlayedMoves(previousGame)
+	if len(playedMoves) > 0 && playedMoves[len(playedMoves)-1].Type == hexit.DoNotSwitchSidesMove {
+		err, previousGame = hexit.Undo(previousGame)
+		if err != nil {
+			return err, game
+		}
+	}
+	return nil, previousGame
+}
+
+// runHumanCommand runs a line typed by the human, and reports whether the board changed
+func runHumanCommand(s *session, line string) (error, bool) {
+	text := strings.ToLower(strings.TrimSpace(line))
+	switch text {
+	case "":
+		return nil, false
+	case "hint":
+		printHint(s)
+		return nil, false
+	case "eval":
+		printEval(s)
+		return nil, false
+	case "undo":
+		err, game := undoHumanMove(s.game, s.human)
+		if err != nil {
+			return err, false
+		}
+		s.game = game
+		// The tree is only for positions after the current one, so start over
+		s.tree = nil
+		return nil, true
+	case "help":
+		fmt.Println(strings.Join(commandHelp, "\n"))
+		return nil, false
+	case "quit", "exit":
+		os.Exit(0)
+	}
+
+	err, move := parseHumanMove(text, s.game)
+	if err != nil {
+		return err, false
+	}
+	err = playMove(s, move)
+	if err != nil {
+		return err, false
+	}
+	if move.Type == hexit.SwitchSidesMove {
+		fmt.Printf("You now play %s\n", hexit.GetPlayerSymbol(getHumanColor(s)))
+	}
+	return nil, true
+}
+
+func main() {
+	boardSize := flag.String("size", fmt.Sprint(hexit.DefaultBoardSize), "board size, such as 7 for a 7x7 board or 4x5 for 4 rows and 5 columns")
+	useColor := flag.Bool("color", false, "color the board with ANSI escape codes")
+	humanPlayer := flag.Int("human", 1, "which player you are: 1 to move first, or 2 to move second")
+	evaluatorName := flag.String("evaluator", "random", "position evaluator for the AI: random, uniform or nn")
+	modelPath := flag.String("model", hexit.DefaultModelPath, "saved model folder for the nn evaluator")
+	numVisits := flag.Int("visits", 1000, "number of search visits per AI move")
+	numThreads := flag.Int("threads", 1, "number of goroutines that search at once")
+	swapRuleName := flag.String("swap", hexit.FormatSwapRule(hexit.SwapSidesRule), "swap rule: swap-sides, swap-pieces or none")
+	flag.Parse()
+	err, numRows, numCols := hexit.ParseBoardDimensions(*boardSize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *humanPlayer != 1 && *humanPlayer != 2 {
+		fmt.Fprintln(os.Stderr, "Human player must be 1 or 2")
+		os.Exit(2)
+	}
+	if *evaluatorName == "nn" {
+		hexit.InitializeModelFromPath(*modelPath)
+	}
+	err, evaluatePosition := hexit.GetEvaluator(*evaluatorName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *numVisits < 1 {
+		fmt.Fprintln(os.Stderr, "Number of visits must be at least 1")
+		os.Exit(2)
+	}
+	if *numThreads < 1 {
+		fmt.Fprintln(os.Stderr, "Number of threads must be at least 1")
+		os.Exit(2)
+	}
+	err, swapRule := hexit.ParseSwapRule(*swapRuleName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if swapRule == hexit.SwapPiecesRule && numRows != numCols {
+		fmt.Fprintln(os.Stderr, "Swapping pieces requires a square board")
+		os.Exit(2)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	s := session{
+		game:             hexit.NewGameWithRules(numRows, numCols, swapRule),
+		human:            byte(*humanPlayer),
+		evaluatePosition: evaluatePosition,
+		numVisits:        *numVisits,
+		numThreads:       *numThreads,
+		useColor:         *useColor,
+	}
+	fmt.Printf("You play %s. Type \"help\" for a list of commands.\n", hexit.GetPlayerSymbol(s.human))
+	showBoard := true
+	for hexit.GetGameWinner(s.game) == 0 {
+		if hexit.GetOriginalPlayer(s.game) == s.human {
+			if showBoard {
+				fmt.Print(renderGame(s.game, s.useColor))
+				if hexit.CanSwap(s.game) {
+					fmt.Println("Type \"swap\" to take over the AI's first stone, or play a move to keep your side")
+				}
+			}
+			line, err := reader.ReadString('\n')
+			if err == io.EOF && strings.TrimSpace(line) == "" {
+				os.Exit(0)
+			}
+			err, showBoard = runHumanCommand(&s, line)
 			if err != nil {
-				panic(err)
+				fmt.Println(err.Error())
 			}
 			continue
 		}
 
-		var move hexit.Move
-		if hexit.GetOriginalPlayer(game) == 1 {
-			err, move = getHumanMove(game.Board)
-			if err != nil {
-				fmt.Println("Invalid move!")
-				continue
-			}
+		move := getEngineMove(&s)
+		if move.Type == hexit.RegularMove {
+			fmt.Printf("AI plays %s\n", hexit.FormatLocation(move.Location))
 		} else {
-			tree := hexit.NewSearchTree(hexit.EvaluatePositionRandomly, game)
-			for i := 0; i < 1000; i++ {
-				hexit.DoVisit(&tree, hexit.EvaluatePositionRandomly)
-			}
-			move = hexit.GetBestMove(&tree)
+			fmt.Println("AI swaps")
 		}
-
-		err, game = hexit.PlayGameMove(game, move.Row, move.Col)
+		err = playMove(&s, move)
 		if err != nil {
 			panic(err)
 		}
+		if s.game.SwitchedSides && move.Type == hexit.SwitchSidesMove {
+			fmt.Printf("You now play %s\n", hexit.GetPlayerSymbol(getHumanColor(&s)))
+		}
+		showBoard = true
 	}
 
-	fmt.Printf("Player %d wins!\n", hexit.GetWinner(game.Board))
+	fmt.Print(renderGame(s.game, s.useColor))
+	if hexit.GetGameWinner(s.game) == getHumanColor(&s) {
+		fmt.Println("You win!")
+	} else {
+		fmt.Println("AI wins!")
+	}
 }
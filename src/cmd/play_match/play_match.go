@@ -1,71 +1,173 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"time"
 
 	hexit "github.com/uyhcire/hexit/src"
 )
 
-func playMatchGame() byte {
+// renderGame draws the board with coordinates, marking the last move and the winning chain
+func renderGame(game hexit.Game) string {
+	options := hexit.RenderOptions{Labels: true, WinningPath: true}
+	if lastMove, ok := hexit.GetLastStoneLocation(game); ok {
+		options.LastMove = &lastMove
+	}
+	return hexit.RenderBoard(game.Board, options)
+}
+
+// searchSettings are how to search: with several goroutines sharing one tree, in batches, or with several trees
+type searchSettings struct {
+	numThreads int
+	batchSize  int
+	numTrees   int
+}
+
+// getPlayerEvaluator gets the evaluator that an original player searches with
+func getPlayerEvaluator(player byte) hexit.Evaluator {
+	if player == 1 {
+		return hexit.EvaluatePositionWithNN
+	}
+	return hexit.EvaluatePositionWithNN
+}
+
+// search adds 100 visits to a player's search tree
+func search(tree *hexit.SearchTree, game hexit.Game, evaluatePosition hexit.Evaluator, settings searchSettings) {
+	if settings.numTrees > 1 {
+		// Root-parallel search only merges the moves from the root, so there's nothing to keep
+		*tree = hexit.DoRootParallelSearch(evaluatePosition, game, 100, settings.numTrees)
+		return
+	}
+	if settings.batchSize > 1 {
+		hexit.DoBatchedVisits(tree, hexit.EvaluatePositionsWithNN, 100, settings.batchSize)
+	} else {
+		hexit.DoParallelVisits(tree, evaluatePosition, 100, settings.numThreads)
+	}
+}
+
+func playMatchGame(numRows uint, numCols uint, swapRule hexit.SwapRule, settings searchSettings) (byte, hexit.Game) {
 	var err error
-	game := hexit.NewGame()
-	for hexit.GetWinner(game.Board) == 0 {
-		hexit.PrintBoard(&game.Board)
-		fmt.Println("")
-		var evaluatePosition hexit.Evaluator
-		if hexit.GetOriginalPlayer(game) == 1 {
-			evaluatePosition = hexit.EvaluatePositionWithNN
-		} else {
-			evaluatePosition = hexit.EvaluatePositionWithNN
+	game := hexit.NewGameWithRules(numRows, numCols, swapRule)
+	// Each player keeps their own search tree from move to move
+	trees := make(map[byte]*hexit.SearchTree)
+	playMove := func(move hexit.GameMove) {
+		err, game = hexit.ApplyGameMove(game, move)
+		if err != nil {
+			panic(err)
+		}
+		for player, tree := range trees {
+			err = hexit.AdvanceSearchTree(tree, getPlayerEvaluator(player), move)
+			if err != nil {
+				panic(err)
+			}
 		}
+	}
 
-		tree := hexit.NewSearchTree(evaluatePosition, game)
-		for i := 0; i < 100; i++ {
-			hexit.DoVisit(&tree, evaluatePosition)
+	for hexit.GetGameWinner(game) == 0 {
+		fmt.Println(renderGame(game))
+		player := hexit.GetOriginalPlayer(game)
+		evaluatePosition := getPlayerEvaluator(player)
+		if trees[player] == nil {
+			tree := hexit.NewSearchTree(evaluatePosition, game)
+			trees[player] = &tree
 		}
-		if game.MoveNum == 2 {
-			if hexit.ShouldSwitchSides(&tree) {
-				err, game = hexit.SwitchSides(game)
-				fmt.Println("Player 2 switched sides!")
+		tree := trees[player]
+		search(tree, game, evaluatePosition, settings)
+
+		if hexit.CanSwap(game) {
+			if hexit.ShouldSwitchSides(tree) {
+				playMove(hexit.GetSwapMove(game))
+				fmt.Println("Player 2 swapped!")
+				if swapRule == hexit.SwapPiecesRule {
+					// Swapping pieces changes the board, so search again from the new position
+					continue
+				}
 			} else {
-				err, game = hexit.DoNotSwitchSides(game)
-			}
-			if err != nil {
-				panic(err)
+				playMove(hexit.GameMove{Type: hexit.DoNotSwitchSidesMove})
 			}
 		}
 
 		var bestMove hexit.Move
 		if game.MoveNum == 1 || game.MoveNum == 3 {
-			bestMove = hexit.GetMoveWithTemperatureOne(&tree)
+			bestMove = hexit.GetMoveWithTemperatureOne(tree)
 		} else {
-			bestMove = hexit.GetBestMove(&tree)
-		}
-
-		err, game = hexit.PlayGameMove(game, bestMove.Row, bestMove.Col)
-		if err != nil {
-			panic(err)
+			bestMove = hexit.GetBestMove(tree)
 		}
+		playMove(hexit.GameMove{Type: hexit.RegularMove, Location: bestMove})
 	}
 
-	winner := hexit.GetWinner(game.Board)
+	fmt.Print(renderGame(game))
+	winner := hexit.GetGameWinner(game)
 	if game.SwitchedSides {
 		winner = hexit.OtherPlayer(winner)
 	}
 	fmt.Printf("Player %d wins!\n", winner)
-	return winner
+	return winner, game
 }
 
 func main() {
+	boardSize := flag.String("size", fmt.Sprint(hexit.DefaultBoardSize), "board size, such as 7 for a 7x7 board or 4x5 for 4 rows and 5 columns")
+	sgfDir := flag.String("sgf-dir", "", "directory to save each game to as an SGF file")
+	swapRuleName := flag.String("swap", hexit.FormatSwapRule(hexit.SwapSidesRule), "swap rule: swap-sides, swap-pieces or none")
+	numThreads := flag.Int("threads", 1, "number of goroutines that search at once")
+	batchSize := flag.Int("batch", 1, "number of positions to evaluate at once")
+	numTrees := flag.Int("trees", 1, "number of independent search trees to search at once and merge")
+	flag.Parse()
+	err, numRows, numCols := hexit.ParseBoardDimensions(*boardSize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	err, swapRule := hexit.ParseSwapRule(*swapRuleName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if swapRule == hexit.SwapPiecesRule && numRows != numCols {
+		fmt.Fprintln(os.Stderr, "Swapping pieces requires a square board")
+		os.Exit(2)
+	}
+	if *numThreads < 1 {
+		fmt.Fprintln(os.Stderr, "Number of threads must be at least 1")
+		os.Exit(2)
+	}
+	if *batchSize < 1 {
+		fmt.Fprintln(os.Stderr, "Batch size must be at least 1")
+		os.Exit(2)
+	}
+	if *numTrees < 1 {
+		fmt.Fprintln(os.Stderr, "Number of trees must be at least 1")
+		os.Exit(2)
+	}
+	numSearchModes := 0
+	for _, setting := range []int{*numThreads, *batchSize, *numTrees} {
+		if setting > 1 {
+			numSearchModes++
+		}
+	}
+	if numSearchModes > 1 {
+		fmt.Fprintln(os.Stderr, "Use only one of -threads, -batch and -trees")
+		os.Exit(2)
+	}
+
 	hexit.InitializeModel()
 
 	rand.Seed(time.Now().UTC().UnixNano())
 
 	playerTwoWinCount := 0
 	for i := 0; i < 1000; i++ {
-		winner := playMatchGame()
+		winner, game := playMatchGame(numRows, numCols, swapRule, searchSettings{*numThreads, *batchSize, *numTrees})
+		if *sgfDir != "" {
+			sgfFilename := filepath.Join(*sgfDir, fmt.Sprintf("game_%d.sgf", i+1))
+			err = hexit.SaveGameRecord(sgfFilename, hexit.NewGameRecord(game, "hexit", "hexit"))
+			if err != nil {
+				panic(err)
+			}
+		}
 		if winner == 2 {
 			playerTwoWinCount++
 		}
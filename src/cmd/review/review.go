@@ -0,0 +1,67 @@
+package main
+
+// Reviews a game record, comparing each move with the move the engine prefers, for example:
+//
+//	go run src/cmd/review/review.go -sgf game.sgf -evaluator nn -visits 5000 -out reviewed.sgf
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	hexit "github.com/uyhcire/hexit/src"
+)
+
+func main() {
+	sgfFilename := flag.String("sgf", "", "game record to review")
+	outputFilename := flag.String("out", "", "file to write the game record to, with the review as comments on the moves")
+	evaluatorName := flag.String("evaluator", "random", "position evaluator: random, uniform or nn")
+	modelPath := flag.String("model", hexit.DefaultModelPath, "saved model folder for the nn evaluator")
+	numVisits := flag.Int("visits", hexit.DefaultReviewOptions.NumVisits, "number of search visits for each position")
+	numThreads := flag.Int("threads", hexit.DefaultReviewOptions.NumThreads, "number of goroutines that search at once")
+	blunderThreshold := flag.Float64("blunder", float64(hexit.DefaultReviewOptions.BlunderThreshold), "flag moves that lose at least this much value, from 0 to 2")
+	winThreshold := flag.Float64("win", float64(hexit.DefaultReviewOptions.WinThreshold), "flag missed wins when a player's value falls below this much, from -1 to 1")
+	flag.Parse()
+	if *sgfFilename == "" {
+		fmt.Fprintln(os.Stderr, "Expected -sgf")
+		os.Exit(2)
+	}
+	if *numVisits < 1 {
+		fmt.Fprintln(os.Stderr, "Number of visits must be at least 1")
+		os.Exit(2)
+	}
+	if *numThreads < 1 {
+		fmt.Fprintln(os.Stderr, "Number of threads must be at least 1")
+		os.Exit(2)
+	}
+	err, record := hexit.LoadGameRecord(*sgfFilename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *evaluatorName == "nn" {
+		hexit.InitializeModelFromPath(*modelPath)
+	}
+	err, evaluatePosition := hexit.GetEvaluator(*evaluatorName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	options := hexit.ReviewOptions{
+		NumVisits:        *numVisits,
+		NumThreads:       *numThreads,
+		BlunderThreshold: float32(*blunderThreshold),
+		WinThreshold:     float32(*winThreshold),
+	}
+	reviews := hexit.ReviewGame(record.Game, evaluatePosition, options)
+	fmt.Print(hexit.FormatReview(reviews))
+
+	if *outputFilename != "" {
+		err = hexit.SaveGameRecord(*outputFilename, hexit.AnnotateGameRecord(record, reviews))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
@@ -1,15 +1,40 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 
 	"github.com/uyhcire/hexit/src"
 )
 
 func main() {
+	boardSize := flag.String("size", fmt.Sprint(hexit.DefaultBoardSize), "board size, such as 7 for a 7x7 board or 4x5 for 4 rows and 5 columns")
+	swapRuleName := flag.String("swap", hexit.FormatSwapRule(hexit.SwapSidesRule), "swap rule: swap-sides, swap-pieces or none")
+	numThreads := flag.Int("threads", 1, "number of goroutines that search at once")
+	flag.Parse()
+	err, numRows, numCols := hexit.ParseBoardDimensions(*boardSize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	err, swapRule := hexit.ParseSwapRule(*swapRuleName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if swapRule == hexit.SwapPiecesRule && numRows != numCols {
+		fmt.Fprintln(os.Stderr, "Swapping pieces requires a square board")
+		os.Exit(2)
+	}
+	if *numThreads < 1 {
+		fmt.Fprintln(os.Stderr, "Number of threads must be at least 1")
+		os.Exit(2)
+	}
+
 	for i := 0; i < 1000; i++ {
 		fmt.Printf("Played %d games\n", i)
 		outputFilename := fmt.Sprintf("%d", i)
-		hexit.GenerateTrainingGame(outputFilename)
+		hexit.GenerateTrainingGame(outputFilename, numRows, numCols, swapRule, *numThreads)
 	}
 }
@@ -0,0 +1,242 @@
+package main
+
+// page is the web page for playing a game. It draws the board as SVG and needs nothing but the JSON API.
+const page = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>hexit</title>
+<style>
+  body { font-family: sans-serif; margin: 2em; color: #222; }
+  #controls > * { margin-right: 0.5em; }
+  #status { margin: 1em 0; font-weight: bold; min-height: 1.2em; }
+  #error { color: #b00; min-height: 1.2em; }
+  #swap { display: none; margin-bottom: 1em; }
+  #moves { font-family: monospace; max-width: 40em; }
+  .cell { stroke: #888; stroke-width: 1; fill: #f4f1e8; }
+  .cell.playable { cursor: pointer; }
+  .cell.playable:hover { fill: #e0dccd; }
+  .stone1 { fill: #c0392b; }
+  .stone2 { fill: #2c5aa0; }
+  .last { stroke: #f1c40f; stroke-width: 3; }
+  .winning { stroke: #111; stroke-width: 3; }
+  .number { fill: #fff; font-size: 11px; text-anchor: middle; dominant-baseline: central; pointer-events: none; }
+  .label { fill: #666; font-size: 12px; text-anchor: middle; dominant-baseline: central; }
+  .edge1 { stroke: #c0392b; stroke-width: 5; stroke-linecap: round; }
+  .edge2 { stroke: #2c5aa0; stroke-width: 5; stroke-linecap: round; }
+</style>
+</head>
+<body>
+<h1>hexit</h1>
+<div id="controls">
+  <label>Board size <input id="size" size="4" value=""></label>
+  <label>You play <select id="human">
+    <option value="1">first (red, top to bottom)</option>
+    <option value="2">second (blue, left to right)</option>
+  </select></label>
+  <label>Swap rule <select id="swapRule">
+    <option value="">default</option>
+    <option value="swap-sides">swap sides</option>
+    <option value="swap-pieces">swap pieces</option>
+    <option value="none">none</option>
+  </select></label>
+  <button id="newGame">New game</button>
+</div>
+<div id="status"></div>
+<div id="swap">
+  <button id="acceptSwap">Swap</button>
+  <button id="declineSwap">Don't swap</button>
+</div>
+<svg id="board" xmlns="http://www.w3.org/2000/svg"></svg>
+<div id="error"></div>
+<p id="moves"></p>
+<script>
+"use strict";
+
+const radius = 22;
+const cellWidth = Math.sqrt(3) * radius;
+const margin = 40;
+const svgNamespace = "http://www.w3.org/2000/svg";
+let state = null;
+let busy = false;
+
+function formatLocation(row, col) {
+  return String.fromCharCode(97 + col) + (row + 1);
+}
+
+function center(row, col) {
+  return [margin + col * cellWidth + row * cellWidth / 2, margin + row * 1.5 * radius];
+}
+
+function hexagonPoints(x, y) {
+  const points = [];
+  for (let i = 0; i < 6; i++) {
+    const angle = Math.PI / 180 * (60 * i - 30);
+    points.push((x + radius * Math.cos(angle)).toFixed(1) + "," + (y + radius * Math.sin(angle)).toFixed(1));
+  }
+  return points.join(" ");
+}
+
+function addElement(parent, name, attributes, text) {
+  const element = document.createElementNS(svgNamespace, name);
+  for (const key in attributes) {
+    element.setAttribute(key, attributes[key]);
+  }
+  if (text !== undefined) {
+    element.textContent = text;
+  }
+  parent.appendChild(element);
+  return element;
+}
+
+function addEdge(svg, player, from, to) {
+  addElement(svg, "line", {"class": "edge" + player, x1: from[0], y1: from[1], x2: to[0], y2: to[1]});
+}
+
+function playerName(player) {
+  return player === 1 ? "red" : "blue";
+}
+
+function drawBoard() {
+  const svg = document.getElementById("board");
+  svg.innerHTML = "";
+  const numRows = state.numRows, numCols = state.numCols;
+  const last = center(numRows - 1, numCols - 1);
+  svg.setAttribute("width", last[0] + margin);
+  svg.setAttribute("height", last[1] + margin);
+
+  // Player 1 connects the top and bottom, and Player 2 connects the left and right
+  const offset = radius * 1.2;
+  const topLeft = center(0, 0), topRight = center(0, numCols - 1);
+  const bottomLeft = center(numRows - 1, 0), bottomRight = last;
+  addEdge(svg, 1, [topLeft[0] - cellWidth / 2, topLeft[1] - offset], [topRight[0] + cellWidth / 2, topRight[1] - offset]);
+  addEdge(svg, 1, [bottomLeft[0] - cellWidth / 2, bottomLeft[1] + offset], [bottomRight[0] + cellWidth / 2, bottomRight[1] + offset]);
+  addEdge(svg, 2, [topLeft[0] - offset, topLeft[1] - radius / 2], [bottomLeft[0] - offset, bottomLeft[1] + radius / 2]);
+  addEdge(svg, 2, [topRight[0] + offset, topRight[1] - radius / 2], [bottomRight[0] + offset, bottomRight[1] + radius / 2]);
+
+  for (let col = 0; col < numCols; col++) {
+    const [x, y] = center(0, col);
+    addElement(svg, "text", {"class": "label", x: x, y: y - radius - 16}, String.fromCharCode(97 + col));
+  }
+  for (let row = 0; row < numRows; row++) {
+    const [x, y] = center(row, 0);
+    addElement(svg, "text", {"class": "label", x: x - cellWidth / 2 - 16, y: y}, String(row + 1));
+  }
+
+  const winningPath = new Set(state.winningPath);
+  for (let row = 0; row < numRows; row++) {
+    for (let col = 0; col < numCols; col++) {
+      const [x, y] = center(row, col);
+      const location = formatLocation(row, col);
+      const stone = state.board[row][col];
+      let className = "cell";
+      if (stone !== 0) {
+        className += " stone" + stone;
+      } else if (state.humanToMove && !busy) {
+        className += " playable";
+      }
+      if (winningPath.has(location)) {
+        className += " winning";
+      } else if (location === state.lastMove) {
+        className += " last";
+      }
+      const cell = addElement(svg, "polygon", {"class": className, points: hexagonPoints(x, y)});
+      addElement(cell, "title", {}, location);
+      if (stone === 0) {
+        cell.addEventListener("click", () => play(location));
+      } else if (state.stoneMoveNums[row][col] > 0) {
+        addElement(svg, "text", {"class": "number", x: x, y: y}, String(state.stoneMoveNums[row][col]));
+      }
+    }
+  }
+}
+
+function describeStatus() {
+  const you = "You are " + playerName(state.humanColor) + ". ";
+  if (state.winner !== 0) {
+    return you + (state.winner === state.humanColor ? "You win!" : "The AI wins!");
+  }
+  if (busy) {
+    return you + "The AI is thinking...";
+  }
+  if (state.humanToMove && state.canSwap) {
+    return you + "Swap to take over the AI's first stone, or play a move to keep your side.";
+  }
+  return you + (state.humanToMove ? "Your move." : "Waiting for the AI.");
+}
+
+function render() {
+  drawBoard();
+  document.getElementById("status").textContent = describeStatus();
+  document.getElementById("swap").style.display = state.humanToMove && state.canSwap && !busy ? "block" : "none";
+  document.getElementById("moves").textContent = state.moves.map((move, i) => (i + 1) + ". " + move).join("  ");
+}
+
+async function request(path, body) {
+  const response = await fetch(path, {
+    method: body === undefined ? "GET" : "POST",
+    headers: {"Content-Type": "application/json"},
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  const json = await response.json();
+  if (!response.ok) {
+    throw new Error(json.error);
+  }
+  return json;
+}
+
+function showError(error) {
+  document.getElementById("error").textContent = error ? error.message : "";
+}
+
+async function letEngineMove() {
+  while (state.winner === 0 && !state.humanToMove) {
+    busy = true;
+    render();
+    try {
+      state = await request("/api/games/" + state.id + "/engine", {});
+    } finally {
+      busy = false;
+    }
+  }
+  render();
+}
+
+async function play(move) {
+  if (busy || !state.humanToMove) {
+    return;
+  }
+  showError(null);
+  try {
+    state = await request("/api/games/" + state.id + "/moves", {move: move});
+    render();
+    await letEngineMove();
+  } catch (error) {
+    showError(error);
+    render();
+  }
+}
+
+async function newGame() {
+  showError(null);
+  try {
+    state = await request("/api/games", {
+      size: document.getElementById("size").value.trim(),
+      human: Number(document.getElementById("human").value),
+      swap: document.getElementById("swapRule").value,
+    });
+    render();
+    await letEngineMove();
+  } catch (error) {
+    showError(error);
+  }
+}
+
+document.getElementById("newGame").addEventListener("click", newGame);
+document.getElementById("acceptSwap").addEventListener("click", () => play("swap"));
+document.getElementById("declineSwap").addEventListener("click", () => play("noswap"));
+newGame();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,408 @@
+package main
+
+// A web server for playing against hexit in a browser.
+// It serves a self-contained page at / and a JSON API under /api/games:
+//
+//	POST /api/games               start a game: {"size": "5", "human": 1, "swap": "swap-sides"}, all optional
+//	GET  /api/games/{id}          get the state of a game
+//	POST /api/games/{id}/moves    play a move for the human: {"move": "c3"}, or "swap" or "noswap" on move 2
+//	POST /api/games/{id}/engine   let the engine play its move
+//
+// Every endpoint responds with the state of the game, or with {"error": "..."}.
+//
+// It also serves the stateless analysis API of the analysis package at POST /api/analyze.
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	hexit "github.com/uyhcire/hexit/src"
+	"github.com/uyhcire/hexit/src/analysis"
+)
+
+// Games are forgotten after this many have been started, oldest first
+const maxNumGames = 1000
+
+// gameSession is a game between someone in a browser and the engine
+type gameSession struct {
+	// Searching takes a while, so each game has its own lock
+	mutex sync.Mutex
+	id    string
+	game  hexit.Game
+	human byte
+}
+
+// server holds the games that are being played, and the engine's settings
+type server struct {
+	mutex            sync.Mutex
+	games            map[string]*gameSession
+	gameIDs          []string
+	numRows          uint
+	numCols          uint
+	swapRule         hexit.SwapRule
+	evaluatePosition hexit.Evaluator
+	numVisits        int
+	numThreads       int
+	// Evaluators that analysis requests can use, by name
+	analysisEvaluators map[string]hexit.Evaluator
+	maxAnalysisVisits  int
+}
+
+// newGameRequest is the body of POST /api/games. Missing fields use the server's flags.
+type newGameRequest struct {
+	Size  string `json:"size"`
+	Human int    `json:"human"`
+	Swap  string `json:"swap"`
+}
+
+// moveRequest is the body of POST /api/games/{id}/moves
+type moveRequest struct {
+	Move string `json:"move"`
+}
+
+// gameState is how a game is sent to the browser. Locations use the same notation as the play command, such as "c3".
+type gameState struct {
+	ID      string `json:"id"`
+	NumRows uint   `json:"numRows"`
+	NumCols uint   `json:"numCols"`
+	// Rows of the board, with 0 for empty, 1 for Player 1's stones and 2 for Player 2's stones
+	Board         [][]int `json:"board"`
+	SwapRule      string  `json:"swapRule"`
+	MoveNum       int     `json:"moveNum"`
+	CurrentPlayer byte    `json:"currentPlayer"`
+	// The color that the human is playing, which changes if either side switches sides
+	HumanColor  byte     `json:"humanColor"`
+	HumanToMove bool     `json:"humanToMove"`
+	CanSwap     bool     `json:"canSwap"`
+	Moves       []string `json:"moves"`
+	// Move number of each stone on the board, or 0 for empty squares
+	StoneMoveNums [][]int  `json:"stoneMoveNums"`
+	LastMove      string   `json:"lastMove,omitempty"`
+	Winner        byte     `json:"winner"`
+	WinningPath   []string `json:"winningPath"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newGameID() string {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bytes)
+}
+
+// getHumanColor gets the color that the human plays, which changes if either side switches sides
+func getHumanColor(session *gameSession) byte {
+	if session.game.SwitchedSides {
+		return hexit.OtherPlayer(session.human)
+	}
+	return session.human
+}
+
+func isHumanToMove(session *gameSession) bool {
+	return hexit.GetGameWinner(session.game) == 0 && hexit.GetOriginalPlayer(session.game) == session.human
+}
+
+func getGameState(session *gameSession) gameState {
+	game := session.game
+	numRows, numCols := hexit.GetBoardDimensions(game.Board)
+	// A []byte would be encoded as a string
+	board := make([][]int, numRows)
+	for i := range board {
+		board[i] = make([]int, numCols)
+		for j := range board[i] {
+			board[i][j] = int(game.Board[i][j])
+		}
+	}
+	moves := make([]string, 0)
+	for _, move := range hexit.GetPlayedMoves(game) {
+		if move.Type != hexit.DoNotSwitchSidesMove {
+			moves = append(moves, hexit.FormatMove(move))
+		}
+	}
+	lastMove := ""
+	if location, ok := hexit.GetLastStoneLocation(game); ok {
+		lastMove = hexit.FormatLocation(location)
+	}
+	winner, winningPath := hexit.GetWinningPath(game.Board)
+	winningPathLocations := make([]string, 0, len(winningPath))
+	for _, location := range winningPath {
+		winningPathLocations = append(winningPathLocations, hexit.FormatLocation(location))
+	}
+
+	return gameState{
+		ID:            session.id,
+		NumRows:       numRows,
+		NumCols:       numCols,
+		Board:         board,
+		SwapRule:      hexit.FormatSwapRule(game.SwapRule),
+		MoveNum:       game.MoveNum,
+		CurrentPlayer: game.CurrentPlayer,
+		HumanColor:    getHumanColor(session),
+		HumanToMove:   isHumanToMove(session),
+		CanSwap:       hexit.CanSwap(game),
+		Moves:         moves,
+		StoneMoveNums: hexit.GetStoneMoveNums(game),
+		LastMove:      lastMove,
+		Winner:        winner,
+		WinningPath:   winningPathLocations,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Println(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	writeJSON(w, statusCode, errorResponse{Error: err.Error()})
+}
+
+// newGame starts a game, with the request's settings or else the server's
+func newGame(s *server, request newGameRequest) (error, *gameSession) {
+	numRows, numCols := s.numRows, s.numCols
+	if request.Size != "" {
+		var err error
+		err, numRows, numCols = hexit.ParseBoardDimensions(request.Size)
+		if err != nil {
+			return err, nil
+		}
+	}
+	human := byte(1)
+	if request.Human != 0 {
+		if request.Human != 1 && request.Human != 2 {
+			return errors.New("Human player must be 1 or 2"), nil
+		}
+		human = byte(request.Human)
+	}
+	swapRule := s.swapRule
+	if request.Swap != "" {
+		var err error
+		err, swapRule = hexit.ParseSwapRule(request.Swap)
+		if err != nil {
+			return err, nil
+		}
+	}
+	if swapRule == hexit.SwapPiecesRule && numRows != numCols {
+		return errors.New("Swapping pieces requires a square board"), nil
+	}
+
+	session := &gameSession{
+		id:    newGameID(),
+		game:  hexit.NewGameWithRules(numRows, numCols, swapRule),
+		human: human,
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.games[session.id] = session
+	s.gameIDs = append(s.gameIDs, session.id)
+	if len(s.gameIDs) > maxNumGames {
+		delete(s.games, s.gameIDs[0])
+		s.gameIDs = s.gameIDs[1:]
+	}
+	return nil, session
+}
+
+func getGameSession(s *server, id string) (error, *gameSession) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session, ok := s.games[id]
+	if !ok {
+		return fmt.Errorf("Unknown game %q", id), nil
+	}
+	return nil, session
+}
+
+// playHumanMove plays a move such as "c3", "swap" or "noswap" for the human
+func playHumanMove(session *gameSession, text string) error {
+	if !isHumanToMove(session) {
+		return errors.New("It's not your turn")
+	}
+	numRows, numCols := hexit.GetBoardDimensions(session.game.Board)
+	err, move := hexit.ParseMove(text, numRows, numCols)
+	if err != nil {
+		return err
+	}
+	if move.Type != hexit.RegularMove && !hexit.CanSwap(session.game) {
+		return errors.New("You can only swap on move 2, in reply to the first stone")
+	}
+	if move.Type == hexit.SwitchSidesMove || move.Type == hexit.SwapPiecesMove {
+		// Swap with whichever variant the game uses
+		move = hexit.GetSwapMove(session.game)
+	}
+	err, session.game = hexit.ApplyGameMove(session.game, move)
+	return err
+}
+
+// playEngineMove searches for the engine's move and plays it. On move 2, it also decides whether to swap.
+func playEngineMove(s *server, session *gameSession) error {
+	if hexit.GetGameWinner(session.game) != 0 {
+		return errors.New("Game is over")
+	}
+	if isHumanToMove(session) {
+		return errors.New("It's your turn")
+	}
+	tree := hexit.NewSearchTree(s.evaluatePosition, session.game)
+	hexit.DoParallelVisits(&tree, s.evaluatePosition, s.numVisits, s.numThreads)
+	move := hexit.GameMove{Type: hexit.RegularMove, Location: hexit.GetBestMove(&tree)}
+	if hexit.CanSwap(session.game) && hexit.ShouldSwitchSides(&tree) {
+		move = hexit.GetSwapMove(session.game)
+	}
+	var err error
+	err, session.game = hexit.ApplyGameMove(session.game, move)
+	return err
+}
+
+func handleNewGame(s *server, w http.ResponseWriter, r *http.Request) {
+	var request newGameRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	err, session := newGame(s, request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, getGameState(session))
+}
+
+// handleGame handles the endpoints of a single game, /api/games/{id} and below
+func handleGame(s *server, w http.ResponseWriter, r *http.Request) {
+	pathParts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/games/"), "/")
+	err, session := getGameSession(s, pathParts[0])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	action := strings.Join(pathParts[1:], "/")
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+	case action == "moves" && r.Method == http.MethodPost:
+		var request moveRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := playHumanMove(session, request.Move); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+	case action == "engine" && r.Method == http.MethodPost:
+		if err := playEngineMove(s, session); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+	default:
+		writeError(w, http.StatusNotFound, errors.New("Not found"))
+		return
+	}
+	writeJSON(w, http.StatusOK, getGameState(session))
+}
+
+func newHandler(s *server) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	})
+	mux.HandleFunc("/api/games", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("Expected POST"))
+			return
+		}
+		handleNewGame(s, w, r)
+	})
+	mux.HandleFunc("/api/games/", func(w http.ResponseWriter, r *http.Request) {
+		handleGame(s, w, r)
+	})
+	mux.Handle("/api/analyze", analysis.NewHandler(s.analysisEvaluators, s.maxAnalysisVisits, s.numThreads))
+	return mux
+}
+
+func main() {
+	address := flag.String("addr", "localhost:8080", "address to listen on")
+	boardSize := flag.String("size", fmt.Sprint(hexit.DefaultBoardSize), "default board size, such as 7 for a 7x7 board or 4x5 for 4 rows and 5 columns")
+	evaluatorName := flag.String("evaluator", "random", "position evaluator for the AI: random, uniform or nn")
+	modelPath := flag.String("model", hexit.DefaultModelPath, "saved model folder for the nn evaluator")
+	numVisits := flag.Int("visits", 1000, "number of search visits per AI move")
+	numThreads := flag.Int("threads", 1, "number of goroutines that search at once, for AI moves and analysis")
+	swapRuleName := flag.String("swap", hexit.FormatSwapRule(hexit.SwapSidesRule), "default swap rule: swap-sides, swap-pieces or none")
+	maxAnalysisVisits := flag.Int("max-analysis-visits", 100000, "most search visits that an analysis request can ask for")
+	flag.Parse()
+	err, numRows, numCols := hexit.ParseBoardDimensions(*boardSize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *evaluatorName == "nn" {
+		hexit.InitializeModelFromPath(*modelPath)
+	}
+	err, evaluatePosition := hexit.GetEvaluator(*evaluatorName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *numVisits < 1 {
+		fmt.Fprintln(os.Stderr, "Number of visits must be at least 1")
+		os.Exit(2)
+	}
+	if *numThreads < 1 {
+		fmt.Fprintln(os.Stderr, "Number of threads must be at least 1")
+		os.Exit(2)
+	}
+	err, swapRule := hexit.ParseSwapRule(*swapRuleName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if swapRule == hexit.SwapPiecesRule && numRows != numCols {
+		fmt.Fprintln(os.Stderr, "Swapping pieces requires a square board")
+		os.Exit(2)
+	}
+
+	// Analysis can use the untrained evaluators, and the model if it was loaded
+	analysisEvaluators := map[string]hexit.Evaluator{
+		"random":  hexit.EvaluatePositionRandomly,
+		"uniform": hexit.EvaluatePositionUniformly,
+	}
+	analysisEvaluators[*evaluatorName] = evaluatePosition
+
+	s := server{
+		games:              make(map[string]*gameSession),
+		numRows:            numRows,
+		numCols:            numCols,
+		swapRule:           swapRule,
+		evaluatePosition:   evaluatePosition,
+		numVisits:          *numVisits,
+		numThreads:         *numThreads,
+		analysisEvaluators: analysisEvaluators,
+		maxAnalysisVisits:  *maxAnalysisVisits,
+	}
+	fmt.Printf("Open http://%s in a browser to play\n", *address)
+	log.Fatal(http.ListenAndServe(*address, newHandler(&s)))
+}
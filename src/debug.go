@@ -45,9 +45,8 @@ func PrintVisitDistribution(node *SearchNode) {
 
 	for _, childNode := range childNodes {
 		fmt.Printf(
-			"(%d, %d) (N: %d) (Q: %2f) (U: %2f)\n",
-			childNode.move.Row,
-			childNode.move.Col,
+			"%s (N: %d) (Q: %2f) (U: %2f)\n",
+			FormatLocation(childNode.move),
 			childNode.n,
 			childNode.q,
 			calculateUctU(childNode, uint(node.n)),
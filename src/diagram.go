@@ -0,0 +1,388 @@
+package hexit
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// DiagramOptions controls what RenderBoardSVG and RenderBoardPNG draw.
+// The zero value draws the board, its edges and its stones.
+type DiagramOptions struct {
+	// Show column letters above the board and row numbers to its left, in the same notation as FormatLocation
+	Labels bool
+	// Numbers to write on the stones, indexed by row and column, such as from GetStoneMoveNums. 0 means no number.
+	MoveNums [][]int
+	// If set, the stone played here is outlined
+	LastMove *BoardLocation
+	// Weights of the squares, indexed by row and column, such as the policy or the visit counts of a search.
+	// Empty squares are shaded in proportion to their weight, relative to the largest weight.
+	Heatmap [][]float32
+}
+
+// Size of the hexagons, in pixels from the center to a corner
+const diagramRadius = 20
+
+var (
+	diagramBackgroundColor = color.RGBA{255, 255, 255, 255}
+	diagramEmptyColor      = color.RGBA{244, 241, 232, 255}
+	diagramGridColor       = color.RGBA{136, 136, 136, 255}
+	diagramHeatColor       = color.RGBA{230, 126, 34, 255}
+	diagramLastMoveColor   = color.RGBA{241, 196, 15, 255}
+	diagramLabelColor      = color.RGBA{102, 102, 102, 255}
+	diagramNumberColor     = color.RGBA{255, 255, 255, 255}
+	// Same colors as RenderBoard: red for Player 1 and blue for Player 2
+	diagramPlayerColors = [3]color.RGBA{{}, {192, 57, 43, 255}, {44, 90, 160, 255}}
+)
+
+type diagramPoint struct {
+	x float64
+	y float64
+}
+
+// diagramLayout is where the squares of a board go in a diagram. Rows are shifted right by half a hexagon each.
+type diagramLayout struct {
+	numRows   uint
+	numCols   uint
+	cellWidth float64
+	margin    float64
+	width     int
+	height    int
+}
+
+func newDiagramLayout(board Board) diagramLayout {
+	numRows, numCols := GetBoardDimensions(board)
+	cellWidth := math.Sqrt(3) * diagramRadius
+	margin := 2.0 * diagramRadius
+	return diagramLayout{
+		numRows:   numRows,
+		numCols:   numCols,
+		cellWidth: cellWidth,
+		margin:    margin,
+		width:     int(math.Ceil(2*margin + cellWidth*float64(numCols) + cellWidth/2*float64(numRows-1))),
+		height:    int(math.Ceil(2*margin + 2*diagramRadius + 1.5*diagramRadius*float64(numRows-1))),
+	}
+}
+
+func getDiagramCellCenter(layout diagramLayout, row uint, col uint) diagramPoint {
+	return diagramPoint{
+		x: layout.margin + layout.cellWidth/2 + layout.cellWidth*(float64(col)+float64(row)/2),
+		y: layout.margin + diagramRadius + 1.5*diagramRadius*float64(row),
+	}
+}
+
+// getDiagramCellCorner gets a corner of a hexagon, counting clockwise from 0 at the top
+func getDiagramCellCorner(layout diagramLayout, row uint, col uint, corner int) diagramPoint {
+	center := getDiagramCellCenter(layout, row, col)
+	angle := math.Pi / 180 * float64(60*corner-90)
+	return diagramPoint{x: center.x + diagramRadius*math.Cos(angle), y: center.y + diagramRadius*math.Sin(angle)}
+}
+
+func getDiagramCellCorners(layout diagramLayout, row uint, col uint) []diagramPoint {
+	corners := make([]diagramPoint, 6)
+	for corner := range corners {
+		corners[corner] = getDiagramCellCorner(layout, row, col, corner)
+	}
+	return corners
+}
+
+// diagramEdge is the outline of one of the board's edges, in the color of the player who connects it
+type diagramEdge struct {
+	player byte
+	points []diagramPoint
+}
+
+// getDiagramEdges gets the board's edges: Player 1 connects the top and bottom, and Player 2 connects the left and right
+func getDiagramEdges(layout diagramLayout) []diagramEdge {
+	lastRow, lastCol := layout.numRows-1, layout.numCols-1
+	top, bottom := diagramEdge{player: 1}, diagramEdge{player: 1}
+	for j := uint(0); j < layout.numCols; j++ {
+		top.points = append(top.points, getDiagramCellCorner(layout, 0, j, 5), getDiagramCellCorner(layout, 0, j, 0), getDiagramCellCorner(layout, 0, j, 1))
+		bottom.points = append(bottom.points, getDiagramCellCorner(layout, lastRow, j, 4), getDiagramCellCorner(layout, lastRow, j, 3), getDiagramCellCorner(layout, lastRow, j, 2))
+	}
+	left, right := diagramEdge{player: 2}, diagramEdge{player: 2}
+	for i := uint(0); i < layout.numRows; i++ {
+		left.points = append(left.points, getDiagramCellCorner(layout, i, 0, 5), getDiagramCellCorner(layout, i, 0, 4))
+		right.points = append(right.points, getDiagramCellCorner(layout, i, lastCol, 1), getDiagramCellCorner(layout, i, lastCol, 2))
+	}
+	return []diagramEdge{top, bottom, left, right}
+}
+
+// getMaxHeatmapWeight finds the largest weight of the heatmap, or 0 if there's no heatmap
+func getMaxHeatmapWeight(heatmap [][]float32) float32 {
+	maxWeight := float32(0)
+	for _, row := range heatmap {
+		for _, weight := range row {
+			if weight > maxWeight {
+				maxWeight = weight
+			}
+		}
+	}
+	return maxWeight
+}
+
+// getDiagramCellColor gets the color that a square is filled with
+func getDiagramCellColor(board Board, row uint, col uint, options DiagramOptions, maxHeatmapWeight float32) color.RGBA {
+	if board[row][col] != 0 {
+		return diagramPlayerColors[board[row][col]]
+	}
+	if maxHeatmapWeight <= 0 {
+		return diagramEmptyColor
+	}
+	fraction := float64(options.Heatmap[row][col] / maxHeatmapWeight)
+	blend := func(from uint8, to uint8) uint8 {
+		return uint8(math.Round(float64(from) + (float64(to)-float64(from))*fraction))
+	}
+	return color.RGBA{
+		blend(diagramEmptyColor.R, diagramHeatColor.R),
+		blend(diagramEmptyColor.G, diagramHeatColor.G),
+		blend(diagramEmptyColor.B, diagramHeatColor.B),
+		255,
+	}
+}
+
+func getDiagramMoveNum(row uint, col uint, options DiagramOptions) int {
+	if options.MoveNums == nil {
+		return 0
+	}
+	return options.MoveNums[row][col]
+}
+
+func getColumnLabelPosition(layout diagramLayout, col uint) diagramPoint {
+	center := getDiagramCellCenter(layout, 0, col)
+	return diagramPoint{x: center.x, y: center.y - diagramRadius - layout.margin/2}
+}
+
+func getRowLabelPosition(layout diagramLayout, row uint) diagramPoint {
+	center := getDiagramCellCenter(layout, row, 0)
+	return diagramPoint{x: center.x - layout.cellWidth/2 - layout.margin/2, y: center.y}
+}
+
+func formatSVGColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func formatSVGPoints(points []diagramPoint) string {
+	formattedPoints := make([]string, len(points))
+	for i, point := range points {
+		formattedPoints[i] = fmt.Sprintf("%.1f,%.1f", point.x, point.y)
+	}
+	return strings.Join(formattedPoints, " ")
+}
+
+// RenderBoardSVG draws the board as an SVG image
+func RenderBoardSVG(board Board, options DiagramOptions) string {
+	layout := newDiagramLayout(board)
+	maxHeatmapWeight := getMaxHeatmapWeight(options.Heatmap)
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", layout.width, layout.height, layout.width, layout.height)
+	fmt.Fprintf(&svg, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", formatSVGColor(diagramBackgroundColor))
+
+	for _, edge := range getDiagramEdges(layout) {
+		fmt.Fprintf(&svg, "<polyline points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"6\" stroke-linecap=\"round\" stroke-linejoin=\"round\"/>\n", formatSVGPoints(edge.points), formatSVGColor(diagramPlayerColors[edge.player]))
+	}
+
+	for i := uint(0); i < layout.numRows; i++ {
+		for j := uint(0); j < layout.numCols; j++ {
+			fmt.Fprintf(&svg, "<polygon points=\"%s\" fill=\"%s\" stroke=\"%s\" stroke-width=\"1\"/>\n", formatSVGPoints(getDiagramCellCorners(layout, i, j)), formatSVGColor(getDiagramCellColor(board, i, j, options, maxHeatmapWeight)), formatSVGColor(diagramGridColor))
+		}
+	}
+	if options.LastMove != nil {
+		fmt.Fprintf(&svg, "<polygon points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"3\"/>\n", formatSVGPoints(getDiagramCellCorners(layout, options.LastMove.Row, options.LastMove.Col)), formatSVGColor(diagramLastMoveColor))
+	}
+
+	textStyle := "font-family=\"sans-serif\" font-size=\"12\" text-anchor=\"middle\" dominant-baseline=\"central\""
+	for i := uint(0); i < layout.numRows; i++ {
+		for j := uint(0); j < layout.numCols; j++ {
+			if moveNum := getDiagramMoveNum(i, j, options); moveNum != 0 {
+				center := getDiagramCellCenter(layout, i, j)
+				fmt.Fprintf(&svg, "<text x=\"%.1f\" y=\"%.1f\" fill=\"%s\" %s>%d</text>\n", center.x, center.y, formatSVGColor(diagramNumberColor), textStyle, moveNum)
+			}
+		}
+	}
+	if options.Labels {
+		for j := uint(0); j < layout.numCols; j++ {
+			position := getColumnLabelPosition(layout, j)
+			fmt.Fprintf(&svg, "<text x=\"%.1f\" y=\"%.1f\" fill=\"%s\" %s>%c</text>\n", position.x, position.y, formatSVGColor(diagramLabelColor), textStyle, 'a'+rune(j))
+		}
+		for i := uint(0); i < layout.numRows; i++ {
+			position := getRowLabelPosition(layout, i)
+			fmt.Fprintf(&svg, "<text x=\"%.1f\" y=\"%.1f\" fill=\"%s\" %s>%d</text>\n", position.x, position.y, formatSVGColor(diagramLabelColor), textStyle, i+1)
+		}
+	}
+
+	svg.WriteString("</svg>\n")
+	return svg.String()
+}
+
+// A small bitmap font for the PNG diagrams, since the standard library has no fonts.
+// Each character is 3 pixels wide and 5 pixels tall. Letters only go up to "s", the last column of the largest board.
+var diagramGlyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'a': {".#.", "#.#", "###", "#.#", "#.#"},
+	'b': {"##.", "#.#", "##.", "#.#", "##."},
+	'c': {".##", "#..", "#..", "#..", ".##"},
+	'd': {"##.", "#.#", "#.#", "#.#", "##."},
+	'e': {"###", "#..", "##.", "#..", "###"},
+	'f': {"###", "#..", "##.", "#..", "#.."},
+	'g': {".##", "#..", "#.#", "#.#", ".##"},
+	'h': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'i': {"###", ".#.", ".#.", ".#.", "###"},
+	'j': {"..#", "..#", "..#", "#.#", ".#."},
+	'k': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'l': {"#..", "#..", "#..", "#..", "###"},
+	'm': {"#.#", "###", "###", "#.#", "#.#"},
+	'n': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'o': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'p': {"##.", "#.#", "##.", "#..", "#.."},
+	'q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'r': {"##.", "#.#", "##.", "#.#", "#.#"},
+	's': {".##", "#..", ".#.", "..#", "##."},
+}
+
+// Each pixel of a glyph is drawn as a square of this many pixels
+const diagramGlyphScale = 2
+
+// drawDiagramText draws text centered on a point, with the bitmap font
+func drawDiagramText(img *image.RGBA, text string, center diagramPoint, c color.RGBA) {
+	glyphWidth, glyphHeight, spacing := 3*diagramGlyphScale, 5*diagramGlyphScale, diagramGlyphScale
+	textWidth := len(text)*(glyphWidth+spacing) - spacing
+	left := int(math.Round(center.x)) - textWidth/2
+	top := int(math.Round(center.y)) - glyphHeight/2
+	for k, character := range text {
+		glyph := diagramGlyphs[character]
+		for glyphRow, line := range glyph {
+			for glyphCol, pixel := range line {
+				if pixel != '#' {
+					continue
+				}
+				x := left + k*(glyphWidth+spacing) + glyphCol*diagramGlyphScale
+				y := top + glyphRow*diagramGlyphScale
+				for dy := 0; dy < diagramGlyphScale; dy++ {
+					for dx := 0; dx < diagramGlyphScale; dx++ {
+						img.SetRGBA(x+dx, y+dy, c)
+					}
+				}
+			}
+		}
+	}
+}
+
+// isInsideConvexPolygon checks whether a point is inside a polygon whose corners go clockwise on screen
+func isInsideConvexPolygon(point diagramPoint, corners []diagramPoint) bool {
+	for i, corner := range corners {
+		next := corners[(i+1)%len(corners)]
+		if (next.x-corner.x)*(point.y-corner.y)-(next.y-corner.y)*(point.x-corner.x) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func getDistanceToSegment(point diagramPoint, start diagramPoint, end diagramPoint) float64 {
+	dx, dy := end.x-start.x, end.y-start.y
+	t := 0.0
+	if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+		t = math.Max(0, math.Min(1, ((point.x-start.x)*dx+(point.y-start.y)*dy)/lengthSquared))
+	}
+	return math.Hypot(point.x-(start.x+t*dx), point.y-(start.y+t*dy))
+}
+
+// getBounds gets the pixels that might be within a distance of the points
+func getBounds(points []diagramPoint, distance float64) image.Rectangle {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, point := range points {
+		minX, minY = math.Min(minX, point.x), math.Min(minY, point.y)
+		maxX, maxY = math.Max(maxX, point.x), math.Max(maxY, point.y)
+	}
+	return image.Rect(
+		int(math.Floor(minX-distance)), int(math.Floor(minY-distance)),
+		int(math.Ceil(maxX+distance))+1, int(math.Ceil(maxY+distance))+1,
+	)
+}
+
+func fillPolygon(img *image.RGBA, corners []diagramPoint, c color.RGBA) {
+	bounds := getBounds(corners, 0).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if isInsideConvexPolygon(diagramPoint{x: float64(x) + 0.5, y: float64(y) + 0.5}, corners) {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// strokeLine draws a line through the points with the given width
+func strokeLine(img *image.RGBA, points []diagramPoint, width float64, c color.RGBA) {
+	bounds := getBounds(points, width/2).Intersect(img.Bounds())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixel := diagramPoint{x: float64(x) + 0.5, y: float64(y) + 0.5}
+			for i := 0; i+1 < len(points); i++ {
+				if getDistanceToSegment(pixel, points[i], points[i+1]) <= width/2 {
+					img.SetRGBA(x, y, c)
+					break
+				}
+			}
+		}
+	}
+}
+
+// RenderBoardPNG draws the board as an image with the same layout as RenderBoardSVG, which can be saved with image/png
+func RenderBoardPNG(board Board, options DiagramOptions) *image.RGBA {
+	layout := newDiagramLayout(board)
+	maxHeatmapWeight := getMaxHeatmapWeight(options.Heatmap)
+
+	img := image.NewRGBA(image.Rect(0, 0, layout.width, layout.height))
+	for y := 0; y < layout.height; y++ {
+		for x := 0; x < layout.width; x++ {
+			img.SetRGBA(x, y, diagramBackgroundColor)
+		}
+	}
+
+	for _, edge := range getDiagramEdges(layout) {
+		strokeLine(img, edge.points, 6, diagramPlayerColors[edge.player])
+	}
+
+	for i := uint(0); i < layout.numRows; i++ {
+		for j := uint(0); j < layout.numCols; j++ {
+			corners := getDiagramCellCorners(layout, i, j)
+			fillPolygon(img, corners, getDiagramCellColor(board, i, j, options, maxHeatmapWeight))
+			strokeLine(img, append(corners, corners[0]), 1, diagramGridColor)
+		}
+	}
+	if options.LastMove != nil {
+		corners := getDiagramCellCorners(layout, options.LastMove.Row, options.LastMove.Col)
+		strokeLine(img, append(corners, corners[0]), 3, diagramLastMoveColor)
+	}
+
+	for i := uint(0); i < layout.numRows; i++ {
+		for j := uint(0); j < layout.numCols; j++ {
+			if moveNum := getDiagramMoveNum(i, j, options); moveNum != 0 {
+				drawDiagramText(img, fmt.Sprint(moveNum), getDiagramCellCenter(layout, i, j), diagramNumberColor)
+			}
+		}
+	}
+	if options.Labels {
+		for j := uint(0); j < layout.numCols; j++ {
+			drawDiagramText(img, fmt.Sprintf("%c", 'a'+rune(j)), getColumnLabelPosition(layout, j), diagramLabelColor)
+		}
+		for i := uint(0); i < layout.numRows; i++ {
+			drawDiagramText(img, fmt.Sprint(i+1), getRowLabelPosition(layout, i), diagramLabelColor)
+		}
+	}
+
+	return img
+}
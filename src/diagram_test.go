@@ -0,0 +1,76 @@
+package hexit
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestRenderBoardSVG(t *testing.T) {
+	board := [][]byte{
+		[]byte{1, 0, 0},
+		[]byte{0, 2, 0},
+		[]byte{0, 0, 0},
+	}
+	moveNums := [][]int{
+		[]int{1, 0, 0},
+		[]int{0, 2, 0},
+		[]int{0, 0, 0},
+	}
+	lastMove := BoardLocation{Row: 1, Col: 1}
+	svg := RenderBoardSVG(board, DiagramOptions{Labels: true, MoveNums: moveNums, LastMove: &lastMove})
+
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Error("Expected an SVG document")
+	}
+	// One hexagon per square, and one more to outline the last move
+	if strings.Count(svg, "<polygon ") != 3*3+1 {
+		t.Errorf("Expected 10 hexagons, got %d", strings.Count(svg, "<polygon "))
+	}
+	if strings.Count(svg, "<polyline ") != 4 {
+		t.Error("Expected the four edges of the board")
+	}
+	if !strings.Contains(svg, ">2</text>") || !strings.Contains(svg, ">c</text>") {
+		t.Error("Expected move numbers and labels")
+	}
+}
+
+func TestRenderBoardPNG(t *testing.T) {
+	board := [][]byte{
+		[]byte{1, 0, 0},
+		[]byte{0, 2, 0},
+		[]byte{0, 0, 0},
+	}
+	heatmap := [][]float32{
+		[]float32{0, 0.5, 0},
+		[]float32{0, 0, 0},
+		[]float32{0, 0, 1},
+	}
+	img := RenderBoardPNG(board, DiagramOptions{Heatmap: heatmap})
+	layout := newDiagramLayout(board)
+	if img.Bounds().Dx() != layout.width || img.Bounds().Dy() != layout.height {
+		t.Error("Expected the image to have the size of the layout")
+	}
+
+	// Check colors a little away from the centers, where there's no text
+	getColorNearCenter := func(row uint, col uint) color.RGBA {
+		center := getDiagramCellCenter(layout, row, col)
+		return img.RGBAAt(int(center.x)+diagramRadius/2, int(center.y))
+	}
+	if getColorNearCenter(0, 0) != diagramPlayerColors[1] {
+		t.Error("Expected Player 1's stone to be red")
+	}
+	if getColorNearCenter(1, 1) != diagramPlayerColors[2] {
+		t.Error("Expected Player 2's stone to be blue")
+	}
+	if getColorNearCenter(2, 2) != diagramHeatColor {
+		t.Error("Expected the square with the largest weight to have the heat color")
+	}
+	if getColorNearCenter(1, 0) != diagramEmptyColor {
+		t.Error("Expected a square with no weight to be empty")
+	}
+	halfway := getColorNearCenter(0, 1)
+	if halfway == getColorNearCenter(1, 0) || halfway == getColorNearCenter(2, 2) {
+		t.Error("Expected a square with half the largest weight to be shaded in between")
+	}
+}
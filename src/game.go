@@ -11,49 +11,363 @@ type Game struct {
 	// They switch sides on move 2, and their next move is considered move 3.
 	SwitchedSides bool
 	Board         Board
+	// Which variant of the swap rule is used. It's set when the game is created.
+	SwapRule SwapRule
+	// Zobrist hash of the game state, covering the board, CurrentPlayer, MoveNum, SwitchedSides and SwapRule.
+	// It's kept up to date by the move functions. If you change the other fields directly, use ComputeGameHash.
+	Hash uint64
+	// Every move played so far, in order, including Player 2's decision on move 2.
+	// After Undo, History also contains the undone moves, which Redo can play again.
+	History []GameMove
+	// Number of moves in History that have been played. The moves after it were undone.
+	HistoryIndex int
+	// The same stones as Board, as a Position, so that GetGameWinner doesn't have to search the board.
+	// The move functions keep it up to date and never change it in place, so games can share it.
+	position *gamePosition
 }
 
-// NewGame creates a new game state
+// gamePosition is the Position that a game keeps next to its board
+type gamePosition struct {
+	// The board that the position has the same stones as. If a game's Board was replaced directly, the position is out of date.
+	board    Board
+	position Position
+}
+
+// GameMoveType is the kind of move recorded in a game's history
+type GameMoveType byte
+
+const (
+	// RegularMove places a stone
+	RegularMove GameMoveType = iota
+	// SwitchSidesMove is Player 2 switching sides on move 2
+	SwitchSidesMove
+	// DoNotSwitchSidesMove is Player 2 deciding not to swap on move 2, under either swap rule
+	DoNotSwitchSidesMove
+	// SwapPiecesMove is Player 2 swapping pieces on move 2
+	SwapPiecesMove
+)
+
+// GameMove is a move recorded in a game's history
+type GameMove struct {
+	Type GameMoveType
+	// Where the stone was placed, for regular moves
+	Location Move
+}
+
+// GetPlayedMoves returns the moves that led to the current game state
+func GetPlayedMoves(game Game) []GameMove {
+	return game.History[:game.HistoryIndex]
+}
+
+// recordMove returns the game's history after playing a move.
+// If the move is the next one that Redo would play, the undone moves are kept. Otherwise they're discarded.
+func recordMove(game Game, move GameMove) []GameMove {
+	if game.HistoryIndex < len(game.History) && game.History[game.HistoryIndex] == move {
+		return game.History
+	}
+	// Always copy, because other games may share the same history
+	history := make([]GameMove, game.HistoryIndex+1)
+	copy(history, game.History[:game.HistoryIndex])
+	history[game.HistoryIndex] = move
+	return history
+}
+
+// NewGame creates a new game state on a board of the default size
 func NewGame() Game {
-	return Game{
+	return NewGameWithBoardSize(DefaultBoardSize)
+}
+
+// NewGameWithBoardSize creates a new game state on an empty square board of the given size
+func NewGameWithBoardSize(boardSize uint) Game {
+	return NewGameWithBoardDimensions(boardSize, boardSize)
+}
+
+// NewGameWithBoardDimensions creates a new game state on an empty board with the given number of rows and columns.
+// Player 1 connects the top and bottom rows, and Player 2 connects the leftmost and rightmost columns.
+func NewGameWithBoardDimensions(numRows uint, numCols uint) Game {
+	return NewGameWithRules(numRows, numCols, SwapSidesRule)
+}
+
+// NewGameWithRules creates a new game state on an empty board, played with the given swap rule
+func NewGameWithRules(numRows uint, numCols uint, swapRule SwapRule) Game {
+	if swapRule == SwapPiecesRule && numRows != numCols {
+		panic("Swapping pieces requires a square board")
+	}
+	game := Game{
 		CurrentPlayer: 1,
 		MoveNum:       1,
 		SwitchedSides: false,
-		Board:         NewBoard(),
+		SwapRule:      swapRule,
+		Board:         NewRectangularBoard(numRows, numCols),
+	}
+	game.position = newGamePosition(game.Board)
+	game.Hash = ComputeGameHash(game)
+	return game
+}
+
+// NewGameFromBoard creates a game that continues from the given position, without any history.
+// Player 1 is to move if both players have the same number of stones, and Player 2 is to move if Player 1 has one more.
+// If Player 1 has the only stone on the board, Player 2 can swap.
+// Under the swap-pieces rule, Player 2 can also have one more stone, after swapping pieces, and then Player 1 is to move.
+// MoveNum comes from GetBoardMoveNum, so MoveNum and Hash are the same as if the game had been played up to this board without swapping.
+func NewGameFromBoard(board Board, swapRule SwapRule) (error, Game) {
+	numRows, numCols := GetBoardDimensions(board)
+	if swapRule == SwapPiecesRule && numRows != numCols {
+		return errors.New("Swapping pieces requires a square board"), Game{}
+	}
+	numStones := [3]int{}
+	for i := uint(0); i < numRows; i++ {
+		for j := uint(0); j < numCols; j++ {
+			if board[i][j] > 2 {
+				return errors.New("Invalid board square"), Game{}
+			}
+			numStones[board[i][j]]++
+		}
+	}
+
+	game := Game{
+		MoveNum:       GetBoardMoveNum(board, swapRule),
+		SwitchedSides: false,
+		SwapRule:      swapRule,
+		Board:         CopyBoard(board),
+	}
+	switch numStones[1] - numStones[2] {
+	case 0:
+		game.CurrentPlayer = 1
+	case 1:
+		game.CurrentPlayer = 2
+	case -1:
+		// After Player 2 swaps pieces, the first stone is theirs, and Player 1 is to move
+		if swapRule != SwapPiecesRule {
+			return errors.New("Player 2 can only have more stones than Player 1 after swapping pieces"), Game{}
+		}
+		game.CurrentPlayer = 1
+	default:
+		return errors.New("Player 1 must have the same number of stones as Player 2, or one more"), Game{}
+	}
+	game.position = newGamePosition(game.Board)
+	game.Hash = ComputeGameHash(game)
+	return nil, game
+}
+
+// newGamePosition creates the Position that a game keeps next to its board
+func newGamePosition(board Board) *gamePosition {
+	return &gamePosition{board: board, position: NewPositionFromBoard(board)}
+}
+
+// hasGamePosition checks whether a game has a Position with the same stones as its board
+func hasGamePosition(game Game) bool {
+	return game.position != nil && len(game.Board) > 0 && len(game.position.board) == len(game.Board) &&
+		&game.position.board[0] == &game.Board[0]
+}
+
+// getGamePosition returns a copy of a game's Position that can be changed in place, such as by the search
+func getGamePosition(game Game) Position {
+	if !hasGamePosition(game) {
+		return NewPositionFromBoard(game.Board)
+	}
+	return CopyPosition(&game.position.position)
+}
+
+// GetGameWinner returns the player that won the game, or 0 if the game is still in progress.
+// It's like GetWinner, but the move functions keep track of connections as they go, so it doesn't need to look at the whole board.
+func GetGameWinner(game Game) byte {
+	if !hasGamePosition(game) {
+		return GetWinner(game.Board)
+	}
+	return GetPositionWinner(&game.position.position)
+}
+
+// GetBoardMoveNum gets the move number of a game that was played up to a board, the way NewGameFromBoard numbers it.
+// Once there are 2 or more stones, Player 2's decision on move 2 counts as a move, unless there's no swap rule.
+// Under the swap-pieces rule, a single stone of Player 2 means that they swapped pieces on move 2, so it's move 3.
+func GetBoardMoveNum(board Board, swapRule SwapRule) int {
+	numStones := [3]int{}
+	for _, row := range board {
+		for _, boardSquareValue := range row {
+			if boardSquareValue <= 2 {
+				numStones[boardSquareValue]++
+			}
+		}
+	}
+	numPlacedStones := numStones[1] + numStones[2]
+	if swapRule == SwapPiecesRule && numPlacedStones == 1 && numStones[2] == 1 {
+		return 3
 	}
+	if swapRule != NoSwapRule && numPlacedStones >= 2 {
+		return numPlacedStones + 2
+	}
+	return numPlacedStones + 1
+}
+
+// getRegularMoveHashChange returns how a game's hash changes when playing a regular move.
+// XOR is its own inverse, so undoing the move changes the hash in the same way.
+func getRegularMoveHashChange(game Game, row uint, col uint) uint64 {
+	return getStoneKey(game.CurrentPlayer, row, col) ^
+		getCurrentPlayerKey(game.CurrentPlayer) ^
+		getCurrentPlayerKey(OtherPlayer(game.CurrentPlayer)) ^
+		getMoveNumKey(game.MoveNum) ^
+		getMoveNumKey(game.MoveNum+1)
 }
 
 // PlayGameMove plays a regular move
 func PlayGameMove(game Game, row uint, col uint) (error, Game) {
-	if game.MoveNum == 2 {
-		return errors.New("Move 2 is when Player 2 decides whether to switch sides"), game
+	if CanSwap(game) {
+		return errors.New("Move 2 is when Player 2 decides whether to swap"), game
+	}
+
+	board := PlayMove(game.Board, game.CurrentPlayer, row, col)
+	return nil, Game{
+		CurrentPlayer: OtherPlayer(game.CurrentPlayer),
+		MoveNum:       game.MoveNum + 1,
+		SwitchedSides: game.SwitchedSides,
+		SwapRule:      game.SwapRule,
+		Board:         board,
+		Hash:          game.Hash ^ getRegularMoveHashChange(game, row, col),
+		History:       recordMove(game, GameMove{Type: RegularMove, Location: Move{Row: row, Col: col}}),
+		HistoryIndex:  game.HistoryIndex + 1,
+		position:      playMoveOnGamePosition(game, board, row, col),
+	}
+}
+
+// playMoveOnGamePosition plays a regular move on a copy of a game's Position, for the board after the move.
+// The game's own Position stays as it was.
+func playMoveOnGamePosition(game Game, board Board, row uint, col uint) *gamePosition {
+	if !hasGamePosition(game) {
+		return newGamePosition(board)
+	}
+	position := CopyPosition(&game.position.position)
+	PlayPositionMove(&position, game.CurrentPlayer, row, col)
+	return &gamePosition{board: board, position: position}
+}
+
+// playGameMoveOnPosition is like PlayGameMove, but plays the move on a Position in place instead of copying the board.
+// The returned game has no Board or History. Use GetPositionBoard if the board is needed.
+func playGameMoveOnPosition(game Game, position *Position, row uint, col uint) (error, Game) {
+	if CanSwap(game) {
+		return errors.New("Move 2 is when Player 2 decides whether to swap"), game
 	}
 
+	PlayPositionMove(position, game.CurrentPlayer, row, col)
 	return nil, Game{
 		CurrentPlayer: OtherPlayer(game.CurrentPlayer),
 		MoveNum:       game.MoveNum + 1,
 		SwitchedSides: game.SwitchedSides,
-		Board:         PlayMove(game.Board, game.CurrentPlayer, row, col),
+		SwapRule:      game.SwapRule,
+		Board:         nil,
+		Hash:          game.Hash ^ getRegularMoveHashChange(game, row, col),
 	}
 }
 
 // SwitchSides switches sides on Player 2's first move
 func SwitchSides(game Game) (error, Game) {
-	if game.MoveNum != 2 {
+	if !CanSwap(game) {
 		return errors.New("Can only switch sides on move 2"), game
 	}
+	if game.SwapRule != SwapSidesRule {
+		return errors.New("Can't switch sides under the swap-pieces rule"), game
+	}
 
 	return nil, Game{
 		CurrentPlayer: game.CurrentPlayer,
 		MoveNum:       game.MoveNum + 1,
 		SwitchedSides: true,
+		SwapRule:      game.SwapRule,
 		Board:         game.Board,
+		Hash: game.Hash ^
+			getMoveNumKey(game.MoveNum) ^
+			getMoveNumKey(game.MoveNum+1) ^
+			getSwitchedSidesKey(game.SwitchedSides) ^
+			getSwitchedSidesKey(true),
+		History:      recordMove(game, GameMove{Type: SwitchSidesMove}),
+		HistoryIndex: game.HistoryIndex + 1,
+		position:     game.position,
+	}
+}
+
+// getSwapPiecesHashChange returns how a game's hash changes when Player 2 swaps pieces.
+// Like getRegularMoveHashChange, undoing the swap changes the hash in the same way.
+func getSwapPiecesHashChange(firstStoneLocation BoardLocation) uint64 {
+	mirroredLocation := mirrorLocation(firstStoneLocation)
+	return getStoneKey(1, firstStoneLocation.Row, firstStoneLocation.Col) ^
+		getStoneKey(2, mirroredLocation.Row, mirroredLocation.Col) ^
+		getCurrentPlayerKey(2) ^
+		getCurrentPlayerKey(1) ^
+		getMoveNumKey(2) ^
+		getMoveNumKey(3)
+}
+
+// getOnlyStoneLocation finds the only stone on the board, which is Player 1's first stone when swapping pieces
+func getOnlyStoneLocation(board Board) (error, BoardLocation) {
+	var location BoardLocation
+	numStones := 0
+	numRows, numCols := GetBoardDimensions(board)
+	for i := uint(0); i < numRows; i++ {
+		for j := uint(0); j < numCols; j++ {
+			if board[i][j] != 0 {
+				location = BoardLocation{Row: i, Col: j}
+				numStones++
+			}
+		}
+	}
+	if numStones != 1 {
+		return errors.New("Expected exactly one stone on the board"), BoardLocation{}
+	}
+	return nil, location
+}
+
+// SwapPieces: on move 2, Player 2 takes over Player 1's first stone.
+// The stone is mirrored into Player 2's color, so it's on the same side of the board from Player 2's point of view.
+// Unlike SwitchSides, the players keep their colors, and Player 1 moves next.
+func SwapPieces(game Game) (error, Game) {
+	if !CanSwap(game) {
+		return errors.New("Can only swap pieces on move 2"), game
+	}
+	if game.SwapRule != SwapPiecesRule {
+		return errors.New("Can't swap pieces under the swap-sides rule"), game
+	}
+	err, firstStoneLocation := getOnlyStoneLocation(game.Board)
+	if err != nil {
+		return err, game
+	}
+	mirroredLocation := mirrorLocation(firstStoneLocation)
+	numRows, numCols := GetBoardDimensions(game.Board)
+	if mirroredLocation.Row >= numRows || mirroredLocation.Col >= numCols {
+		return errors.New("Swapping pieces requires a square board"), game
+	}
+
+	board := CopyBoard(game.Board)
+	board[firstStoneLocation.Row][firstStoneLocation.Col] = 0
+	board[mirroredLocation.Row][mirroredLocation.Col] = 2
+	return nil, Game{
+		CurrentPlayer: 1,
+		MoveNum:       game.MoveNum + 1,
+		SwitchedSides: false,
+		SwapRule:      game.SwapRule,
+		Board:         board,
+		Hash:          game.Hash ^ getSwapPiecesHashChange(firstStoneLocation),
+		History:       recordMove(game, GameMove{Type: SwapPiecesMove}),
+		HistoryIndex:  game.HistoryIndex + 1,
+		position:      newGamePosition(board),
+	}
+}
+
+// Swap swaps on move 2, with SwitchSides or SwapPieces depending on the game's swap rule
+func Swap(game Game) (error, Game) {
+	return ApplyGameMove(game, GetSwapMove(game))
+}
+
+// GetSwapMove gets the move that swaps under the game's swap rule
+func GetSwapMove(game Game) GameMove {
+	if game.SwapRule == SwapPiecesRule {
+		return GameMove{Type: SwapPiecesMove}
 	}
+	return GameMove{Type: SwitchSidesMove}
 }
 
-// DoNotSwitchSides: Player 2 decided not to switch sides
+// DoNotSwitchSides: Player 2 decided not to swap
 func DoNotSwitchSides(game Game) (error, Game) {
-	if game.MoveNum != 2 {
+	if !CanSwap(game) {
 		return errors.New("Can only switch sides on move 2"), game
 	}
 
@@ -61,7 +375,16 @@ func DoNotSwitchSides(game Game) (error, Game) {
 		CurrentPlayer: game.CurrentPlayer,
 		MoveNum:       game.MoveNum + 1,
 		SwitchedSides: false,
+		SwapRule:      game.SwapRule,
 		Board:         game.Board,
+		Hash: game.Hash ^
+			getMoveNumKey(game.MoveNum) ^
+			getMoveNumKey(game.MoveNum+1) ^
+			getSwitchedSidesKey(game.SwitchedSides) ^
+			getSwitchedSidesKey(false),
+		History:      recordMove(game, GameMove{Type: DoNotSwitchSidesMove}),
+		HistoryIndex: game.HistoryIndex + 1,
+		position:     game.position,
 	}
 }
 
@@ -73,3 +396,241 @@ func GetOriginalPlayer(game Game) byte {
 	}
 	return game.CurrentPlayer
 }
+
+// getMirroredStoneLocation finds the stone that Player 2 took over by swapping pieces, with the move at index i of the game's played moves.
+// If the game started from a position with Player 1's first stone, that stone isn't in the history, so it's found on the board after the swap.
+func getMirroredStoneLocation(game Game, i int) BoardLocation {
+	if i > 0 {
+		return mirrorLocation(GetPlayedMoves(game)[i-1].Location)
+	}
+	err, gameAfterSwap := ReplayTo(game, 1)
+	if err != nil {
+		panic(err)
+	}
+	err, location := getOnlyStoneLocation(gameAfterSwap.Board)
+	if err != nil {
+		panic(err)
+	}
+	return location
+}
+
+// GetLastStoneLocation finds the stone that was placed most recently, if there is one.
+// After Player 2 swaps pieces, it's the mirrored stone.
+func GetLastStoneLocation(game Game) (BoardLocation, bool) {
+	playedMoves := GetPlayedMoves(game)
+	for i := len(playedMoves) - 1; i >= 0; i-- {
+		switch playedMoves[i].Type {
+		case RegularMove:
+			return playedMoves[i].Location, true
+		case SwapPiecesMove:
+			return getMirroredStoneLocation(game, i), true
+		}
+	}
+	return BoardLocation{}, false
+}
+
+// countWrittenMoves counts moves the way they're written down, where deciding not to swap isn't a move
+func countWrittenMoves(moves []GameMove) int {
+	numWrittenMoves := 0
+	for _, move := range moves {
+		if move.Type != DoNotSwitchSidesMove {
+			numWrittenMoves++
+		}
+	}
+	return numWrittenMoves
+}
+
+// ReplayToWrittenMove is like ReplayTo, but counts moves the way they're written down, like GetStoneMoveNums does.
+// After the first move, Player 2 hasn't decided whether to swap yet.
+func ReplayToWrittenMove(game Game, numMoves int) (error, Game) {
+	numHistoryMoves := 0
+	numWrittenMoves := 0
+	for numHistoryMoves < len(game.History) && numWrittenMoves < numMoves {
+		if game.History[numHistoryMoves].Type != DoNotSwitchSidesMove {
+			numWrittenMoves++
+		}
+		numHistoryMoves++
+	}
+	if numMoves < 0 || numWrittenMoves < numMoves {
+		return errors.New("Can only replay to a point in the game's history"), game
+	}
+	return ReplayTo(game, numHistoryMoves)
+}
+
+// LoadGame gets a game from either a position string, as written by FormatPosition, or a game record file.
+// A game record is replayed to numMoves moves, counted like ReplayToWrittenMove does, or kept whole if numMoves is negative.
+// The swap rule is only used for a position string, since a game record has its own.
+func LoadGame(position string, sgfFilename string, numMoves int, swapRule SwapRule) (error, Game) {
+	if (position == "") == (sgfFilename == "") {
+		return errors.New("Expected either a position or a game record"), Game{}
+	}
+	if position != "" {
+		err, board := ParsePosition(position)
+		if err != nil {
+			return err, Game{}
+		}
+		return NewGameFromBoard(board, swapRule)
+	}
+
+	err, record := LoadGameRecord(sgfFilename)
+	if err != nil {
+		return err, Game{}
+	}
+	if numMoves < 0 {
+		return nil, record.Game
+	}
+	return ReplayToWrittenMove(record.Game, numMoves)
+}
+
+// GetStoneMoveNums numbers each stone on the board by the move that placed it, with 0 for empty squares.
+// Moves are counted the way they're written down: swapping is move 2, and deciding not to swap isn't a move.
+// After Player 2 swaps pieces, the mirrored stone is numbered 2.
+func GetStoneMoveNums(game Game) [][]int {
+	numRows, numCols := GetBoardDimensions(game.Board)
+	stoneMoveNums := make([][]int, numRows)
+	for i := range stoneMoveNums {
+		stoneMoveNums[i] = make([]int, numCols)
+	}
+	playedMoves := GetPlayedMoves(game)
+	moveNum := 0
+	for i, move := range playedMoves {
+		switch move.Type {
+		case RegularMove:
+			moveNum++
+			stoneMoveNums[move.Location.Row][move.Location.Col] = moveNum
+		case SwitchSidesMove:
+			moveNum++
+		case SwapPiecesMove:
+			moveNum++
+			mirroredLocation := getMirroredStoneLocation(game, i)
+			firstStoneLocation := mirrorLocation(mirroredLocation)
+			stoneMoveNums[firstStoneLocation.Row][firstStoneLocation.Col] = 0
+			stoneMoveNums[mirroredLocation.Row][mirroredLocation.Col] = moveNum
+		}
+	}
+	return stoneMoveNums
+}
+
+// ApplyGameMove plays any kind of move, such as a move parsed with ParseMove.
+// A regular move on move 2 means that Player 2 decided not to swap.
+func ApplyGameMove(game Game, move GameMove) (error, Game) {
+	switch move.Type {
+	case RegularMove:
+		numRows, numCols := GetBoardDimensions(game.Board)
+		if move.Location.Row >= numRows || move.Location.Col >= numCols {
+			return errors.New("Location is not on the board"), game
+		}
+		if game.Board[move.Location.Row][move.Location.Col] != 0 {
+			return errors.New("Location is already occupied"), game
+		}
+		var err error
+		gameBeforeMove := game
+		if CanSwap(game) {
+			err, gameBeforeMove = DoNotSwitchSides(game)
+			if err != nil {
+				return err, game
+			}
+		}
+		err, gameAfterMove := PlayGameMove(gameBeforeMove, move.Location.Row, move.Location.Col)
+		if err != nil {
+			return err, game
+		}
+		return nil, gameAfterMove
+	case SwitchSidesMove:
+		return SwitchSides(game)
+	case DoNotSwitchSidesMove:
+		return DoNotSwitchSides(game)
+	case SwapPiecesMove:
+		return SwapPieces(game)
+	}
+	return errors.New("Invalid move type"), game
+}
+
+// Undo takes back the last move. It can be played again with Redo.
+func Undo(game Game) (error, Game) {
+	if game.HistoryIndex == 0 {
+		return errors.New("There are no moves to undo"), game
+	}
+
+	move := game.History[game.HistoryIndex-1]
+	previousGame := Game{
+		CurrentPlayer: game.CurrentPlayer,
+		MoveNum:       game.MoveNum - 1,
+		SwitchedSides: false,
+		SwapRule:      game.SwapRule,
+		Board:         game.Board,
+		History:       game.History,
+		HistoryIndex:  game.HistoryIndex - 1,
+		position:      game.position,
+	}
+	switch move.Type {
+	case RegularMove:
+		previousGame.CurrentPlayer = OtherPlayer(game.CurrentPlayer)
+		previousGame.SwitchedSides = game.SwitchedSides
+		previousGame.Board = CopyBoard(game.Board)
+		previousGame.Board[move.Location.Row][move.Location.Col] = 0
+		// Union-find can't take a stone back, so start over from the board
+		previousGame.position = newGamePosition(previousGame.Board)
+		previousGame.Hash = game.Hash ^ getRegularMoveHashChange(previousGame, move.Location.Row, move.Location.Col)
+	case SwitchSidesMove, DoNotSwitchSidesMove:
+		previousGame.Hash = game.Hash ^
+			getMoveNumKey(game.MoveNum) ^
+			getMoveNumKey(previousGame.MoveNum) ^
+			getSwitchedSidesKey(game.SwitchedSides)
+	case SwapPiecesMove:
+		err, mirroredLocation := getOnlyStoneLocation(game.Board)
+		if err != nil {
+			return err, game
+		}
+		firstStoneLocation := mirrorLocation(mirroredLocation)
+		previousGame.CurrentPlayer = 2
+		previousGame.Board = CopyBoard(game.Board)
+		previousGame.Board[mirroredLocation.Row][mirroredLocation.Col] = 0
+		previousGame.Board[firstStoneLocation.Row][firstStoneLocation.Col] = 1
+		previousGame.position = newGamePosition(previousGame.Board)
+		previousGame.Hash = game.Hash ^ getSwapPiecesHashChange(firstStoneLocation)
+	}
+	return nil, previousGame
+}
+
+// Redo plays the last move that was taken back with Undo
+func Redo(game Game) (error, Game) {
+	if game.HistoryIndex == len(game.History) {
+		return errors.New("There are no moves to redo"), game
+	}
+
+	move := game.History[game.HistoryIndex]
+	switch move.Type {
+	case RegularMove:
+		return PlayGameMove(game, move.Location.Row, move.Location.Col)
+	case SwitchSidesMove:
+		return SwitchSides(game)
+	case DoNotSwitchSidesMove:
+		return DoNotSwitchSides(game)
+	case SwapPiecesMove:
+		return SwapPieces(game)
+	}
+	panic("unreachable")
+}
+
+// ReplayTo undoes or redoes moves until exactly numMoves moves of the game's history have been played
+func ReplayTo(game Game, numMoves int) (error, Game) {
+	if numMoves < 0 || numMoves > len(game.History) {
+		return errors.New("Can only replay to a point in the game's history"), game
+	}
+
+	var err error
+	for game.HistoryIndex > numMoves {
+		err, game = Undo(game)
+		if err != nil {
+			return err, game
+		}
+	}
+	for game.HistoryIndex < numMoves {
+		err, game = Redo(game)
+		if err != nil {
+			return err, game
+		}
+	}
+	return nil, game
+}
@@ -1,6 +1,12 @@
 package hexit
 
-import "testing"
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestNewGame(t *testing.T) {
 	game := NewGame()
@@ -88,6 +94,103 @@ func TestDoNotSwitchSides(t *testing.T) {
 	}
 }
 
+func TestSwapPieces(t *testing.T) {
+	game := NewGameWithRules(5, 5, SwapPiecesRule)
+	err, game := PlayGameMove(game, 0, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	err, game = SwapPieces(game)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if game.Board[0][1] != 0 || game.Board[1][0] != 2 {
+		t.Error("Expected the first stone to be mirrored into Player 2's color")
+	}
+	if game.CurrentPlayer != 1 || game.MoveNum != 3 {
+		t.Error("After swapping pieces, Player 1 should play move 3")
+	}
+	if game.SwitchedSides || GetOriginalPlayer(game) != 1 {
+		t.Error("Swapping pieces shouldn't switch sides")
+	}
+	if game.Hash != ComputeGameHash(game) {
+		t.Error("Incremental hash doesn't match after swapping pieces")
+	}
+
+	err, undoneGame := Undo(game)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if undoneGame.Board[0][1] != 1 || undoneGame.Board[1][0] != 0 || undoneGame.CurrentPlayer != 2 || undoneGame.MoveNum != 2 {
+		t.Error("Undo should put the first stone back")
+	}
+	if undoneGame.Hash != ComputeGameHash(undoneGame) {
+		t.Error("Incremental hash doesn't match after undoing a swap")
+	}
+}
+
+func TestSwapFollowsSwapRule(t *testing.T) {
+	game := NewGameWithRules(5, 5, SwapPiecesRule)
+	err, game := PlayGameMove(game, 0, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, _ = SwitchSides(game)
+	if err == nil {
+		t.Error("Shouldn't be able to switch sides under the swap-pieces rule")
+	}
+	err, swappedGame := Swap(game)
+	if err != nil || swappedGame.Board[1][0] != 2 {
+		t.Error("Expected Swap to swap pieces")
+	}
+
+	game = NewGame()
+	err, game = PlayGameMove(game, 0, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, _ = SwapPieces(game)
+	if err == nil {
+		t.Error("Shouldn't be able to swap pieces under the swap-sides rule")
+	}
+	err, swappedGame = Swap(game)
+	if err != nil || !swappedGame.SwitchedSides {
+		t.Error("Expected Swap to switch sides")
+	}
+}
+
+func TestNoSwapRule(t *testing.T) {
+	game := NewGameWithRules(5, 5, NoSwapRule)
+	err, game := PlayGameMove(game, 0, 0)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if CanSwap(game) {
+		t.Error("Player 2 shouldn't be able to swap")
+	}
+	err, _ = Swap(game)
+	if err == nil {
+		t.Error("Player 2 shouldn't be able to swap")
+	}
+	err, _ = DoNotSwitchSides(game)
+	if err == nil {
+		t.Error("Player 2 shouldn't be able to decide whether to swap")
+	}
+
+	err, game = PlayGameMove(game, 1, 1)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if game.Board[1][1] != 2 || game.CurrentPlayer != 1 || game.MoveNum != 3 {
+		t.Error("Player 2 should place a stone on move 2")
+	}
+	if len(GetPlayedMoves(game)) != 2 {
+		t.Error("Expected only the two stones in the history")
+	}
+}
+
 func TestGetOriginalPlayer(t *testing.T) {
 	game := NewGame()
 	err, game := PlayGameMove(game, 0, 0)
@@ -104,3 +207,479 @@ func TestGetOriginalPlayer(t *testing.T) {
 		t.Error("The original Player 1 is playing as Player 2")
 	}
 }
+
+func TestHistory(t *testing.T) {
+	game := NewGame()
+	err, game := PlayGameMove(game, 0, 0)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, game = SwitchSides(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, game = PlayGameMove(game, 1, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	expectedMoves := []GameMove{
+		{Type: RegularMove, Location: Move{Row: 0, Col: 0}},
+		{Type: SwitchSidesMove},
+		{Type: RegularMove, Location: Move{Row: 1, Col: 1}},
+	}
+	playedMoves := GetPlayedMoves(game)
+	if len(playedMoves) != len(expectedMoves) {
+		t.Fatalf("Expected %d moves in the history but got %d", len(expectedMoves), len(playedMoves))
+	}
+	for i := range expectedMoves {
+		if playedMoves[i] != expectedMoves[i] {
+			t.Errorf("Expected move %d to be %v but got %v", i, expectedMoves[i], playedMoves[i])
+		}
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	game := NewGame()
+	err, game := PlayGameMove(game, 0, 0)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, gameAfterSwitchingSides := SwitchSides(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, finalGame := PlayGameMove(gameAfterSwitchingSides, 1, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	err, game = Undo(finalGame)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.Board[1][1] != 0 || game.CurrentPlayer != 2 || game.MoveNum != 3 || !game.SwitchedSides {
+		t.Error("Expected to be back to the position after switching sides")
+	}
+	if game.Hash != gameAfterSwitchingSides.Hash {
+		t.Error("Undo should restore the hash")
+	}
+	if finalGame.Board[1][1] != 2 {
+		t.Error("Undo should not change the original game")
+	}
+
+	err, game = Undo(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.MoveNum != 2 || game.SwitchedSides {
+		t.Error("Expected Player 2 to be able to decide whether to switch sides again")
+	}
+
+	err, game = Undo(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.Board[0][0] != 0 || game.CurrentPlayer != 1 || game.MoveNum != 1 || game.Hash != NewGame().Hash {
+		t.Error("Expected to be back at the start of the game")
+	}
+	err, _ = Undo(game)
+	if err == nil {
+		t.Error("Should not be able to undo past the start of the game")
+	}
+
+	for i := 0; i < 3; i++ {
+		err, game = Redo(game)
+		if err != nil {
+			t.Error(err.Error())
+		}
+	}
+	if game.Board[1][1] != 2 || game.MoveNum != 4 || game.Hash != finalGame.Hash {
+		t.Error("Redo should replay every undone move")
+	}
+	err, _ = Redo(game)
+	if err == nil {
+		t.Error("Should not be able to redo past the last move")
+	}
+}
+
+func TestNewMoveDiscardsUndoneMoves(t *testing.T) {
+	game := NewGame()
+	err, game := PlayGameMove(game, 0, 0)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, game = DoNotSwitchSides(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, game = PlayGameMove(game, 1, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	err, game = Undo(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, branchedGame := PlayGameMove(game, 2, 2)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(branchedGame.History) != 3 || branchedGame.History[2].Location != (Move{Row: 2, Col: 2}) {
+		t.Error("Playing a different move should replace the undone move")
+	}
+
+	err, sameGame := PlayGameMove(game, 1, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if len(sameGame.History) != 3 || sameGame.History[2].Location != (Move{Row: 1, Col: 1}) {
+		t.Error("Branching from a game should not change other games that share its history")
+	}
+}
+
+func TestReplayTo(t *testing.T) {
+	game := NewGame()
+	err, game := PlayGameMove(game, 0, 0)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, game = DoNotSwitchSides(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, game = PlayGameMove(game, 1, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	err, game = ReplayTo(game, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.MoveNum != 2 || game.Board[0][0] != 1 || game.Board[1][1] != 0 {
+		t.Error("Expected to replay to the position after the first move")
+	}
+
+	err, game = ReplayTo(game, 3)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.MoveNum != 4 || game.Board[1][1] != 2 {
+		t.Error("Expected to replay to the end of the game")
+	}
+
+	err, _ = ReplayTo(game, 4)
+	if err == nil {
+		t.Error("Should not be able to replay past the end of the history")
+	}
+}
+
+func TestGetLastStoneLocation(t *testing.T) {
+	game := NewGameWithRules(5, 5, SwapPiecesRule)
+	if _, ok := GetLastStoneLocation(game); ok {
+		t.Error("No stones have been placed yet")
+	}
+
+	err, game := PlayGameMove(game, 0, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if location, ok := GetLastStoneLocation(game); !ok || location != (BoardLocation{Row: 0, Col: 1}) {
+		t.Error("Expected the first stone to be the last one placed")
+	}
+
+	err, game = SwapPieces(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if location, ok := GetLastStoneLocation(game); !ok || location != (BoardLocation{Row: 1, Col: 0}) {
+		t.Error("Expected the mirrored stone to be the last one placed")
+	}
+
+	// The first stone isn't in the history of a game that started from a position
+	err, game = NewGameFromBoard([][]byte{[]byte{0, 1}, []byte{0, 0}}, SwapPiecesRule)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, game = SwapPieces(game)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if location, ok := GetLastStoneLocation(game); !ok || location != (BoardLocation{Row: 1, Col: 0}) {
+		t.Error("Expected the mirrored stone to be the last one placed")
+	}
+}
+
+func TestNewGameFromBoard(t *testing.T) {
+	board := [][]byte{
+		[]byte{1, 0, 0},
+		[]byte{0, 2, 0},
+		[]byte{0, 1, 0},
+	}
+	err, game := NewGameFromBoard(board, SwapSidesRule)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	// Player 2's decision on move 2 counts as a move
+	if game.CurrentPlayer != 2 || game.MoveNum != 5 || CanSwap(game) {
+		t.Error("Expected Player 2 to play move 5")
+	}
+	if game.Hash != ComputeGameHash(game) {
+		t.Error("Expected the game's hash to be up to date")
+	}
+
+	err, game = NewGameFromBoard([][]byte{[]byte{0, 1}, []byte{0, 0}}, SwapSidesRule)
+	if err != nil || !CanSwap(game) {
+		t.Error("Expected Player 2 to be able to swap after the first stone")
+	}
+
+	err, _ = NewGameFromBoard([][]byte{[]byte{0, 2}, []byte{0, 0}}, SwapSidesRule)
+	if err == nil {
+		t.Error("Player 2 can't have more stones than Player 1")
+	}
+}
+
+// A board should get the same hash as a game that was played up to it, so that hash lookups find it
+func TestNewGameFromBoardAfterSwappingPieces(t *testing.T) {
+	board := [][]byte{
+		[]byte{0, 0, 0},
+		[]byte{2, 0, 0},
+		[]byte{0, 0, 0},
+	}
+	err, game := NewGameFromBoard(board, SwapPiecesRule)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if game.CurrentPlayer != 1 || game.MoveNum != 3 || CanSwap(game) {
+		t.Error("Expected Player 1 to play move 3")
+	}
+
+	err, playedGame := PlayGameMove(NewGameWithRules(3, 3, SwapPiecesRule), 0, 1)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, playedGame = SwapPieces(playedGame)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if game.Hash != playedGame.Hash {
+		t.Error("Expected the same hash as the game where Player 2 swapped pieces")
+	}
+
+	err, playedGame = PlayGameMove(playedGame, 2, 2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, playedGame = PlayGameMove(playedGame, 0, 2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, game = NewGameFromBoard(playedGame.Board, SwapPiecesRule)
+	if err != nil || game.CurrentPlayer != 1 || game.Hash != playedGame.Hash {
+		t.Error("Expected Player 1 to move when Player 2 has more stones, with the played game's hash")
+	}
+
+	err, _ = NewGameFromBoard(board, SwapSidesRule)
+	if err == nil {
+		t.Error("Player 2 can only have more stones after swapping pieces")
+	}
+}
+
+func TestNewGameFromBoardMatchesPlayedGame(t *testing.T) {
+	for _, swapRule := range []SwapRule{SwapSidesRule, SwapPiecesRule, NoSwapRule} {
+		playedGame := NewGameWithRules(3, 3, swapRule)
+		moves := []GameMove{
+			{Type: RegularMove, Location: Move{Row: 0, Col: 0}},
+			{Type: RegularMove, Location: Move{Row: 1, Col: 1}},
+			{Type: RegularMove, Location: Move{Row: 2, Col: 1}},
+		}
+		for numMoves, move := range moves {
+			var err error
+			err, playedGame = ApplyGameMove(playedGame, move)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			err, game := NewGameFromBoard(playedGame.Board, swapRule)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if game.MoveNum != playedGame.MoveNum || game.Hash != playedGame.Hash {
+				t.Errorf(
+					"With swap rule %s after %d moves, expected move %d with the played game's hash, got move %d",
+					FormatSwapRule(swapRule), numMoves+1, playedGame.MoveNum, game.MoveNum,
+				)
+			}
+		}
+	}
+}
+
+func TestGetStoneMoveNums(t *testing.T) {
+	game := NewGameWithRules(3, 3, SwapPiecesRule)
+	err, game := PlayGameMove(game, 0, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, game = SwapPieces(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, game = PlayGameMove(game, 2, 2)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	stoneMoveNums := GetStoneMoveNums(game)
+	if stoneMoveNums[0][1] != 0 || stoneMoveNums[1][0] != 2 || stoneMoveNums[2][2] != 3 {
+		t.Errorf("Unexpected move numbers %v", stoneMoveNums)
+	}
+
+	// Moves are numbered from the start of the history, which can start with the swap
+	err, game = NewGameFromBoard([][]byte{[]byte{0, 1}, []byte{0, 0}}, SwapPiecesRule)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, game = SwapPieces(game)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	stoneMoveNums = GetStoneMoveNums(game)
+	if stoneMoveNums[0][1] != 0 || stoneMoveNums[1][0] != 1 {
+		t.Errorf("Expected the mirrored stone to be numbered, got %v", stoneMoveNums)
+	}
+}
+
+func TestReplayToWrittenMove(t *testing.T) {
+	game := NewGame()
+	for _, move := range []GameMove{
+		GameMove{Type: RegularMove, Location: Move{Row: 0, Col: 0}},
+		GameMove{Type: RegularMove, Location: Move{Row: 1, Col: 1}},
+		GameMove{Type: RegularMove, Location: Move{Row: 2, Col: 2}},
+	} {
+		var err error
+		err, game = ApplyGameMove(game, move)
+		if err != nil {
+			t.Error(err.Error())
+		}
+	}
+
+	// Player 2's first stone comes with deciding not to swap, so there are 4 moves in the history
+	err, replayedGame := ReplayToWrittenMove(game, 2)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if replayedGame.HistoryIndex != 3 || replayedGame.Board[1][1] != 2 || replayedGame.Board[2][2] != 0 {
+		t.Error("Expected to replay the first two stones")
+	}
+	err, replayedGame = ReplayToWrittenMove(game, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if replayedGame.HistoryIndex != 1 || !CanSwap(replayedGame) {
+		t.Error("Expected Player 2 to be able to swap after the first move")
+	}
+	err, _ = ReplayToWrittenMove(game, 4)
+	if err == nil {
+		t.Error("Should not be able to replay past the end of the game")
+	}
+}
+
+func TestLoadGame(t *testing.T) {
+	err, game := LoadGame("x--/-o-/---", "", -1, NoSwapRule)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if game.CurrentPlayer != 1 || game.MoveNum != 3 || game.SwapRule != NoSwapRule {
+		t.Error("Expected Player 1 to play move 3 without the swap rule")
+	}
+
+	directory, err := ioutil.TempDir("", "hexit")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(directory)
+	sgfFilename := filepath.Join(directory, "game.sgf")
+	playedGame := NewGameWithBoardSize(3)
+	for _, location := range []Move{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 2}} {
+		err, playedGame = ApplyGameMove(playedGame, GameMove{Type: RegularMove, Location: location})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if err := SaveGameRecord(sgfFilename, GameRecord{Game: playedGame}); err != nil {
+		t.Fatal(err.Error())
+	}
+	err, game = LoadGame("", sgfFilename, 2, NoSwapRule)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if game.Board[1][1] != 2 || game.Board[2][2] != 0 || game.SwapRule != SwapSidesRule {
+		t.Error("Expected the game record to be replayed to its second stone, with its own swap rule")
+	}
+	err, game = LoadGame("", sgfFilename, -1, NoSwapRule)
+	if err != nil || game.Board[2][2] != 1 {
+		t.Error("Expected the whole game record")
+	}
+
+	err, _ = LoadGame("", "", -1, SwapSidesRule)
+	if err == nil {
+		t.Error("Expected either a position or a game record to be required")
+	}
+	err, _ = LoadGame("x--/-o-/---", sgfFilename, -1, SwapSidesRule)
+	if err == nil {
+		t.Error("Expected a position and a game record together to be rejected")
+	}
+}
+
+// The game's Position should always agree with the board, through moves, swaps, Undo and Redo
+func TestGetGameWinnerMatchesGetWinner(t *testing.T) {
+	random := rand.New(rand.NewSource(0))
+	for gameNum := 0; gameNum < 20; gameNum++ {
+		swapRule := []SwapRule{SwapSidesRule, SwapPiecesRule, NoSwapRule}[gameNum%3]
+		game := NewGameWithRules(5, 5, swapRule)
+		for GetWinner(game.Board) == 0 {
+			var err error
+			if CanSwap(game) && random.Intn(2) == 0 {
+				err, game = Swap(game)
+			} else if game.HistoryIndex > 0 && random.Intn(5) == 0 {
+				err, game = Undo(game)
+			} else {
+				emptyLocations := make([]Move, 0)
+				for row := uint(0); row < 5; row++ {
+					for col := uint(0); col < 5; col++ {
+						if game.Board[row][col] == 0 {
+							emptyLocations = append(emptyLocations, Move{Row: row, Col: col})
+						}
+					}
+				}
+				move := GameMove{Type: RegularMove, Location: emptyLocations[random.Intn(len(emptyLocations))]}
+				err, game = ApplyGameMove(game, move)
+			}
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if GetGameWinner(game) != GetWinner(game.Board) {
+				t.Fatalf("Expected winner %d after %v, got %d", GetWinner(game.Board), GetPlayedMoves(game), GetGameWinner(game))
+			}
+		}
+	}
+
+	// A board that was replaced directly doesn't match the game's Position anymore
+	game := NewGameWithBoardSize(2)
+	game.Board = [][]byte{
+		[]byte{1, 0},
+		[]byte{1, 0},
+	}
+	if GetGameWinner(game) != 1 {
+		t.Error("Expected the winner of the replaced board")
+	}
+	err, game := PlayGameMove(game, 0, 1)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if GetGameWinner(game) != 1 {
+		t.Error("Expected the move to be played on the replaced board")
+	}
+}
@@ -2,12 +2,21 @@ package hexit
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Board encoding: 0 = blank, 1 = Player 1, 2 = Player 2
 // First index is the number of rows from the top
 // Second index is the number of columns from the left
-type Board = [5][5]byte
+// Every row has the same number of columns, but the number of rows and columns can differ.
+type Board = [][]byte
+
+// DefaultBoardSize is the board size used when none is specified
+const DefaultBoardSize = 5
+
+// MaxBoardSize is the largest number of rows or columns the engine supports
+const MaxBoardSize = 19
 
 // BoardLocation is a location on a board
 type BoardLocation struct {
@@ -18,52 +27,6 @@ type BoardLocation struct {
 // Move that a player can make
 type Move = BoardLocation
 
-func formatBoardSquare(boardSquareValue byte) string {
-	if boardSquareValue == 1 {
-		return "X"
-	} else if boardSquareValue == 2 {
-		return "O"
-	} else {
-		return "-"
-	}
-}
-
-// PrintBoard prints the board to the console
-func PrintBoard(board *Board) {
-	fmt.Printf(
-		"%s %s %s %s %s\n"+
-			" %s %s %s %s %s\n"+
-			"  %s %s %s %s %s\n"+
-			"   %s %s %s %s %s\n"+
-			"    %s %s %s %s %s\n",
-		formatBoardSquare(board[0][0]),
-		formatBoardSquare(board[0][1]),
-		formatBoardSquare(board[0][2]),
-		formatBoardSquare(board[0][3]),
-		formatBoardSquare(board[0][4]),
-		formatBoardSquare(board[1][0]),
-		formatBoardSquare(board[1][1]),
-		formatBoardSquare(board[1][2]),
-		formatBoardSquare(board[1][3]),
-		formatBoardSquare(board[1][4]),
-		formatBoardSquare(board[2][0]),
-		formatBoardSquare(board[2][1]),
-		formatBoardSquare(board[2][2]),
-		formatBoardSquare(board[2][3]),
-		formatBoardSquare(board[2][4]),
-		formatBoardSquare(board[3][0]),
-		formatBoardSquare(board[3][1]),
-		formatBoardSquare(board[3][2]),
-		formatBoardSquare(board[3][3]),
-		formatBoardSquare(board[3][4]),
-		formatBoardSquare(board[4][0]),
-		formatBoardSquare(board[4][1]),
-		formatBoardSquare(board[4][2]),
-		formatBoardSquare(board[4][3]),
-		formatBoardSquare(board[4][4]),
-	)
-}
-
 // OtherPlayer returns the other player
 func OtherPlayer(player byte) byte {
 	if player == 1 {
@@ -75,18 +38,59 @@ func OtherPlayer(player byte) byte {
 	}
 }
 
-// NewBoard creates an empty board
-func NewBoard() Board {
-	return [5][5]byte{}
+// NewBoard creates an empty square board with the given number of rows and columns
+func NewBoard(boardSize uint) Board {
+	return NewRectangularBoard(boardSize, boardSize)
+}
+
+// NewRectangularBoard creates an empty board that can have a different number of rows and columns
+func NewRectangularBoard(numRows uint, numCols uint) Board {
+	if numRows == 0 || numRows > MaxBoardSize || numCols == 0 || numCols > MaxBoardSize {
+		panic("Invalid board size")
+	}
+	board := make(Board, numRows)
+	for i := range board {
+		board[i] = make([]byte, numCols)
+	}
+	return board
+}
+
+// GetBoardDimensions returns the number of rows and columns of a board
+func GetBoardDimensions(board Board) (uint, uint) {
+	if len(board) == 0 {
+		return 0, 0
+	}
+	return uint(len(board)), uint(len(board[0]))
+}
+
+// ParseBoardDimensions parses a board size such as "7" (a 7x7 board) or "4x5" (4 rows and 5 columns)
+func ParseBoardDimensions(boardSize string) (error, uint, uint) {
+	dimensions := strings.Split(boardSize, "x")
+	if len(dimensions) == 1 {
+		dimensions = append(dimensions, dimensions[0])
+	}
+	if len(dimensions) != 2 {
+		return fmt.Errorf("Invalid board size %q", boardSize), 0, 0
+	}
+	numRows, err := strconv.ParseUint(dimensions[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("Invalid board size %q", boardSize), 0, 0
+	}
+	numCols, err := strconv.ParseUint(dimensions[1], 10, 32)
+	if err != nil {
+		return fmt.Errorf("Invalid board size %q", boardSize), 0, 0
+	}
+	if numRows == 0 || numRows > MaxBoardSize || numCols == 0 || numCols > MaxBoardSize {
+		return fmt.Errorf("Board size %q must be between 1 and %d in each dimension", boardSize, MaxBoardSize), 0, 0
+	}
+	return nil, uint(numRows), uint(numCols)
 }
 
 // CopyBoard returns a copy of an existing board
 func CopyBoard(board Board) Board {
-	newBoard := NewBoard()
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
-			newBoard[i][j] = board[i][j]
-		}
+	newBoard := NewRectangularBoard(GetBoardDimensions(board))
+	for i := range board {
+		copy(newBoard[i], board[i])
 	}
 	return newBoard
 }
@@ -101,7 +105,7 @@ func PlayMove(board Board, player byte, row uint, col uint) Board {
 	return newBoard
 }
 
-func getAdjacentLocations(location BoardLocation) []BoardLocation {
+func getAdjacentLocations(location BoardLocation, numRows uint, numCols uint) []BoardLocation {
 	adjacent := make([]BoardLocation, 0)
 
 	// Row above
@@ -136,7 +140,7 @@ func getAdjacentLocations(location BoardLocation) []BoardLocation {
 
 	validAdjacent := make([]BoardLocation, 0)
 	for _, adjacentLocation := range adjacent {
-		if adjacentLocation.Row >= 0 && adjacentLocation.Row < 5 && adjacentLocation.Col >= 0 && adjacentLocation.Col < 5 {
+		if adjacentLocation.Row >= 0 && adjacentLocation.Row < numRows && adjacentLocation.Col >= 0 && adjacentLocation.Col < numCols {
 			validAdjacent = append(validAdjacent, adjacentLocation)
 		}
 	}
@@ -144,21 +148,42 @@ func getAdjacentLocations(location BoardLocation) []BoardLocation {
 	return validAdjacent
 }
 
-// Do breadth-first search to determine if the player has a connected path from one side of the board to the other.
-func didPlayerWin(
+// Do breadth-first search to find a connected path of the player's stones from one side of the board to the other.
+// Returns nil if there's no such path. Breadth-first search finds the shortest path, so the path has no unnecessary stones.
+func findWinningPath(
 	board Board,
 	player byte,
 	// Side of the board to start looking from
 	startingLocations []BoardLocation,
 	// Is this location on the other side of the board?
 	isLocationWinning func(BoardLocation) bool,
-) bool {
-	visited := [5][5]bool{}
+) []BoardLocation {
+	numRows, numCols := GetBoardDimensions(board)
+	visited := make([][]bool, numRows)
+	// Location that each visited location was reached from
+	previous := make([][]BoardLocation, numRows)
+	for i := range visited {
+		visited[i] = make([]bool, numCols)
+		previous[i] = make([]BoardLocation, numCols)
+	}
+	getPath := func(location BoardLocation) []BoardLocation {
+		path := []BoardLocation{location}
+		for !isStartingLocation(location, startingLocations) {
+			location = previous[location.Row][location.Col]
+			path = append([]BoardLocation{location}, path...)
+		}
+		return path
+	}
+
 	locationQueue := make([]BoardLocation, 0)
 	for _, location := range startingLocations {
 		row := location.Row
 		col := location.Col
 		if board[row][col] == player {
+			// On a board with a single row or column, the starting side is also the winning side
+			if isLocationWinning(location) {
+				return []BoardLocation{location}
+			}
 			locationQueue = append(locationQueue, location)
 			visited[row][col] = true
 		}
@@ -167,76 +192,104 @@ func didPlayerWin(
 	for len(locationQueue) != 0 {
 		location := locationQueue[0]
 		locationQueue = locationQueue[1:]
-		for _, adjacentLocation := range getAdjacentLocations(location) {
+		for _, adjacentLocation := range getAdjacentLocations(location, numRows, numCols) {
 			if board[adjacentLocation.Row][adjacentLocation.Col] != player {
 				continue
 			}
-			if isLocationWinning(adjacentLocation) {
-				return true
-			}
 			if !visited[adjacentLocation.Row][adjacentLocation.Col] {
 				visited[adjacentLocation.Row][adjacentLocation.Col] = true
+				previous[adjacentLocation.Row][adjacentLocation.Col] = location
+				if isLocationWinning(adjacentLocation) {
+					return getPath(adjacentLocation)
+				}
 				locationQueue = append(locationQueue, adjacentLocation)
 			}
 		}
 	}
 
+	return nil
+}
+
+func isStartingLocation(location BoardLocation, startingLocations []BoardLocation) bool {
+	for _, startingLocation := range startingLocations {
+		if location == startingLocation {
+			return true
+		}
+	}
 	return false
 }
 
-// PlayerOneWins returns true if Player 1 has connected the top to the bottom
-func PlayerOneWins(board Board) bool {
-	return didPlayerWin(
+func getPlayerOneWinningPath(board Board) []BoardLocation {
+	numRows, numCols := GetBoardDimensions(board)
+	topRow := make([]BoardLocation, 0, numCols)
+	for col := uint(0); col < numCols; col++ {
+		topRow = append(topRow, BoardLocation{Row: 0, Col: col})
+	}
+	return findWinningPath(
 		board,
 		1,
-		[]BoardLocation{
-			BoardLocation{Row: 0, Col: 0},
-			BoardLocation{Row: 0, Col: 1},
-			BoardLocation{Row: 0, Col: 2},
-			BoardLocation{Row: 0, Col: 3},
-			BoardLocation{Row: 0, Col: 4},
-		},
+		topRow,
 		func(location BoardLocation) bool {
-			return location.Row == 4
+			return location.Row == numRows-1
 		})
 }
 
-// PlayerTwoWins returns true if Player 2 has connected the left to the right
-func PlayerTwoWins(board Board) bool {
-	return didPlayerWin(
+func getPlayerTwoWinningPath(board Board) []BoardLocation {
+	numRows, numCols := GetBoardDimensions(board)
+	leftColumn := make([]BoardLocation, 0, numRows)
+	for row := uint(0); row < numRows; row++ {
+		leftColumn = append(leftColumn, BoardLocation{Row: row, Col: 0})
+	}
+	return findWinningPath(
 		board,
 		2,
-		[]BoardLocation{
-			BoardLocation{Row: 0, Col: 0},
-			BoardLocation{Row: 1, Col: 0},
-			BoardLocation{Row: 2, Col: 0},
-			BoardLocation{Row: 3, Col: 0},
-			BoardLocation{Row: 4, Col: 0},
-		},
+		leftColumn,
 		func(location BoardLocation) bool {
-			return location.Col == 4
+			return location.Col == numCols-1
 		})
 }
 
+// PlayerOneWins returns true if Player 1 has connected the top to the bottom
+func PlayerOneWins(board Board) bool {
+	return getPlayerOneWinningPath(board) != nil
+}
+
+// PlayerTwoWins returns true if Player 2 has connected the left to the right
+func PlayerTwoWins(board Board) bool {
+	return getPlayerTwoWinningPath(board) != nil
+}
+
+// GetWinningPath returns the player that won the game and a shortest chain of their stones that connects their sides of the board,
+// in order from the top (for Player 1) or the left (for Player 2). If the game is still in progress, it returns 0 and nil.
+func GetWinningPath(board Board) (byte, []BoardLocation) {
+	if path := getPlayerOneWinningPath(board); path != nil {
+		return 1, path
+	}
+	if path := getPlayerTwoWinningPath(board); path != nil {
+		return 2, path
+	}
+	return 0, nil
+}
+
 // GetWinner returns the player that won the game, or 0 if the game is still in progress.
+// It views the board as a Position, whose union-find finds connections without searching the board.
+// For a Game, GetGameWinner is faster, since the game keeps its Position up to date as moves are played.
 func GetWinner(board Board) byte {
-	if PlayerOneWins(board) {
-		return 1
-	} else if PlayerTwoWins(board) {
-		return 2
-	} else {
-		return 0
-	}
+	position := NewPositionFromBoard(board)
+	return GetPositionWinner(&position)
 }
 
+// FlipBoardForTrainingData makes the board look like it's Player 1's move.
+// For Player 2, the board is transposed, so a board with R rows and C columns becomes a board with C rows and R columns.
 func FlipBoardForTrainingData(board Board, player byte) Board {
 	if player == 1 {
 		return board
 	}
 
-	flippedBoard := NewBoard()
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
+	numRows, numCols := GetBoardDimensions(board)
+	flippedBoard := NewRectangularBoard(numCols, numRows)
+	for i := uint(0); i < numRows; i++ {
+		for j := uint(0); j < numCols; j++ {
 			originalBoardSquare := board[i][j]
 			if originalBoardSquare != 0 {
 				flippedBoard[j][i] = OtherPlayer(originalBoardSquare)
@@ -250,11 +303,12 @@ func FlipBoardForTrainingData(board Board, player byte) Board {
 
 func GetOccupiedSquaresForNN(board Board, player byte) ([]float32, []float32) {
 	board = FlipBoardForTrainingData(board, player)
-	squaresOccupiedByMyself := make([]float32, 5*5)
-	squaresOccupiedByOtherPlayer := make([]float32, 5*5)
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
-			boardSquareIndex := i*5 + j
+	numRows, numCols := GetBoardDimensions(board)
+	squaresOccupiedByMyself := make([]float32, numRows*numCols)
+	squaresOccupiedByOtherPlayer := make([]float32, numRows*numCols)
+	for i := uint(0); i < numRows; i++ {
+		for j := uint(0); j < numCols; j++ {
+			boardSquareIndex := i*numCols + j
 			if board[i][j] == 1 {
 				squaresOccupiedByMyself[boardSquareIndex] = 1.0
 			}
@@ -3,14 +3,14 @@ package hexit
 import "testing"
 
 func TestNewBoard(t *testing.T) {
-	board := NewBoard()
+	board := NewBoard(DefaultBoardSize)
 	if board[0][0] != 0 {
 		t.Error("Board should be empty")
 	}
 }
 
 func TestPlayMove(t *testing.T) {
-	board := NewBoard()
+	board := NewBoard(DefaultBoardSize)
 	board = PlayMove(board, 1, 0, 0)
 	if board[0][0] != 1 {
 		t.Error("Expected Player 1 to fill a grid location")
@@ -25,12 +25,12 @@ func TestPlayMove(t *testing.T) {
      X - - - -
 */
 func TestPlayerOneWins(t *testing.T) {
-	board := [5][5]byte{
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
+	board := [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
 	}
 	if PlayerOneWins(board) {
 		t.Error("Player 1 hasn't won yet!")
@@ -49,12 +49,12 @@ func TestPlayerOneWins(t *testing.T) {
      - - - X -
 */
 func TestPlayerOneWinsWithWindingPath(t *testing.T) {
-	board := [5][5]byte{
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 1, 1, 1},
-		[5]byte{1, 1, 0, 0, 1},
-		[5]byte{0, 0, 0, 1, 0},
-		[5]byte{0, 0, 0, 0, 0},
+	board := [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 1, 1, 1},
+		[]byte{1, 1, 0, 0, 1},
+		[]byte{0, 0, 0, 1, 0},
+		[]byte{0, 0, 0, 0, 0},
 	}
 	if PlayerOneWins(board) {
 		t.Error("Player 1 hasn't won yet!")
@@ -73,12 +73,12 @@ func TestPlayerOneWinsWithWindingPath(t *testing.T) {
      - - - - -
 */
 func TestPlayerTwoWins(t *testing.T) {
-	board := [5][5]byte{
-		[5]byte{2, 2, 2, 2, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
+	board := [][]byte{
+		[]byte{2, 2, 2, 2, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
 	}
 	if PlayerTwoWins(board) {
 		t.Error("Player 2 hasn't won yet!")
@@ -89,13 +89,179 @@ func TestPlayerTwoWins(t *testing.T) {
 	}
 }
 
+func TestPlayerOneWinsOnLargerBoard(t *testing.T) {
+	board := NewBoard(7)
+	for row := uint(0); row < 6; row++ {
+		board = PlayMove(board, 1, row, 3)
+	}
+	if PlayerOneWins(board) {
+		t.Error("Player 1 hasn't reached the last row yet!")
+	}
+	board = PlayMove(board, 1, 6, 2)
+	if !PlayerOneWins(board) {
+		t.Error("Expected Player 1 to be the winner")
+	}
+	if PlayerTwoWins(board) {
+		t.Error("Player 2 has no stones on the board")
+	}
+}
+
+/*
+ - X - - -
+  - X - - -
+   X - - - -
+    X - - - -
+*/
+func TestPlayerOneWinsOnRectangularBoard(t *testing.T) {
+	board := [][]byte{
+		[]byte{0, 1, 0, 0, 0},
+		[]byte{0, 1, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+	}
+	if PlayerOneWins(board) {
+		t.Error("Player 1 hasn't won yet!")
+	}
+	board = PlayMove(board, 1, 3, 0)
+	if !PlayerOneWins(board) {
+		t.Error("Expected Player 1 to connect the top and bottom of a 4x5 board")
+	}
+}
+
+/*
+ O O O O
+  - - - -
+   - - - -
+    - - - -
+     - - - -
+*/
+func TestPlayerTwoWinsOnRectangularBoard(t *testing.T) {
+	board := NewRectangularBoard(5, 4)
+	for col := uint(0); col < 3; col++ {
+		board = PlayMove(board, 2, 0, col)
+	}
+	if PlayerTwoWins(board) {
+		t.Error("Player 2 hasn't won yet!")
+	}
+	board = PlayMove(board, 2, 0, 3)
+	if !PlayerTwoWins(board) {
+		t.Error("Expected Player 2 to connect the left and right of a 5x4 board")
+	}
+	if PlayerOneWins(board) {
+		t.Error("Player 1 has no stones on the board")
+	}
+}
+
+// On a board with a single row, the top and the bottom are the same row, so any stone of Player 1 connects them.
+// Likewise for Player 2 on a board with a single column.
+func TestWinnerOnSingleRowOrColumnBoard(t *testing.T) {
+	board := NewRectangularBoard(1, 3)
+	board = PlayMove(board, 2, 0, 0)
+	board = PlayMove(board, 2, 0, 1)
+	if GetWinner(board) != 0 {
+		t.Error("Player 2 hasn't won yet!")
+	}
+	board = PlayMove(board, 1, 0, 2)
+	winner, path := GetWinningPath(board)
+	if GetWinner(board) != 1 || winner != 1 {
+		t.Error("Expected a single stone of Player 1 to win on a board with a single row")
+	}
+	if len(path) != 1 || path[0] != (BoardLocation{Row: 0, Col: 2}) {
+		t.Errorf("Expected the winning chain to be the single stone, got %v", path)
+	}
+
+	board = NewRectangularBoard(3, 1)
+	board = PlayMove(board, 2, 1, 0)
+	winner, path = GetWinningPath(board)
+	if GetWinner(board) != 2 || winner != 2 {
+		t.Error("Expected a single stone of Player 2 to win on a board with a single column")
+	}
+	if len(path) != 1 || path[0] != (BoardLocation{Row: 1, Col: 0}) {
+		t.Errorf("Expected the winning chain to be the single stone, got %v", path)
+	}
+}
+
+func TestParseBoardDimensions(t *testing.T) {
+	err, numRows, numCols := ParseBoardDimensions("7")
+	if err != nil || numRows != 7 || numCols != 7 {
+		t.Error("Expected a 7x7 board")
+	}
+	err, numRows, numCols = ParseBoardDimensions("4x5")
+	if err != nil || numRows != 4 || numCols != 5 {
+		t.Error("Expected a board with 4 rows and 5 columns")
+	}
+	for _, invalidBoardSize := range []string{"", "0", "20", "4x", "x5", "4x5x6", "abc"} {
+		err, _, _ = ParseBoardDimensions(invalidBoardSize)
+		if err == nil {
+			t.Errorf("Expected %q to be an invalid board size", invalidBoardSize)
+		}
+	}
+}
+
 func TestGetWinnerEmptyBoard(t *testing.T) {
-	board := NewBoard()
+	board := NewBoard(DefaultBoardSize)
 	if GetWinner(board) != 0 {
 		t.Error("The game just started, there's no winner yet!")
 	}
 }
 
+/*
+ X X - - -
+  X X - - -
+   X - - - -
+    X X - - -
+     - X - - -
+*/
+func TestGetWinningPath(t *testing.T) {
+	board := [][]byte{
+		[]byte{1, 1, 0, 0, 0},
+		[]byte{1, 1, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 1, 0, 0, 0},
+		[]byte{0, 1, 0, 0, 0},
+	}
+	winner, path := GetWinningPath(board)
+	if winner != 1 {
+		t.Error("Expected Player 1 to be the winner")
+	}
+	expectedPath := []BoardLocation{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 2, Col: 0}, {Row: 3, Col: 0}, {Row: 3, Col: 1}, {Row: 4, Col: 1}}
+	if len(path) != len(expectedPath) {
+		t.Fatalf("Expected a chain of %d stones, got %d", len(expectedPath), len(path))
+	}
+	for i := range expectedPath {
+		if path[i] != expectedPath[i] {
+			t.Errorf("Expected %v at position %d of the chain, got %v", expectedPath[i], i, path[i])
+		}
+	}
+}
+
+/*
+ - - - - - -
+  X - - - - -
+   O O O O O O
+    - - - - X -
+*/
+func TestGetWinningPathPlayerTwo(t *testing.T) {
+	board := [][]byte{
+		[]byte{0, 0, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0, 0},
+		[]byte{2, 2, 2, 2, 2, 2},
+		[]byte{0, 0, 0, 0, 1, 0},
+	}
+	winner, path := GetWinningPath(board)
+	if winner != 2 || len(path) != 6 {
+		t.Error("Expected Player 2 to win with a chain of 6 stones")
+	}
+	if path[0].Col != 0 || path[5].Col != 5 {
+		t.Error("Expected the chain to go from left to right")
+	}
+
+	winner, path = GetWinningPath(NewBoard(DefaultBoardSize))
+	if winner != 0 || path != nil {
+		t.Error("Nobody has won yet")
+	}
+}
+
 // Suppose Player 1 sees this board:
 //
 // X - O - O
@@ -114,20 +280,20 @@ func TestGetWinnerEmptyBoard(t *testing.T) {
 //
 // See training_game.proto for a more detailed explanation
 func TestFlipBoardForTrainingData(t *testing.T) {
-	board := [5][5]byte{
-		[5]byte{1, 0, 2, 0, 2},
-		[5]byte{1, 2, 0, 0, 1},
-		[5]byte{2, 0, 0, 1, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 1, 2, 1},
+	board := [][]byte{
+		[]byte{1, 0, 2, 0, 2},
+		[]byte{1, 2, 0, 0, 1},
+		[]byte{2, 0, 0, 1, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 1, 2, 1},
 	}
 
-	expectedBoard := [5][5]byte{
-		[5]byte{2, 2, 1, 0, 0},
-		[5]byte{0, 1, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 2},
-		[5]byte{0, 0, 2, 0, 1},
-		[5]byte{1, 2, 0, 0, 2},
+	expectedBoard := [][]byte{
+		[]byte{2, 2, 1, 0, 0},
+		[]byte{0, 1, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 2},
+		[]byte{0, 0, 2, 0, 1},
+		[]byte{1, 2, 0, 0, 2},
 	}
 
 	flippedBoard := FlipBoardForTrainingData(board, 2)
@@ -142,3 +308,33 @@ func TestFlipBoardForTrainingData(t *testing.T) {
 		}
 	}
 }
+
+// Player 2 sees a board with the rows and columns swapped
+func TestFlipRectangularBoardForTrainingData(t *testing.T) {
+	board := [][]byte{
+		[]byte{1, 0, 2},
+		[]byte{0, 1, 0},
+	}
+
+	expectedBoard := [][]byte{
+		[]byte{2, 0},
+		[]byte{0, 2},
+		[]byte{1, 0},
+	}
+
+	flippedBoard := FlipBoardForTrainingData(board, 2)
+	numRows, numCols := GetBoardDimensions(flippedBoard)
+	if numRows != 3 || numCols != 2 {
+		t.Fatalf("Expected a 3x2 board but got %dx%d", numRows, numCols)
+	}
+	for i := uint(0); i < numRows; i++ {
+		for j := uint(0); j < numCols; j++ {
+			if flippedBoard[i][j] != expectedBoard[i][j] {
+				t.Errorf(
+					"Expected position (%d, %d) to have value %d but got %d\n",
+					i, j, expectedBoard[i][j], flippedBoard[i][j],
+				)
+			}
+		}
+	}
+}
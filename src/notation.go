@@ -0,0 +1,124 @@
+package hexit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Moves are written in the notation used by other Hex tools:
+// the column is a letter starting from "a" on the left, and the row is a number starting from 1 at the top.
+// For example, "c3" is Row 2, Col 2, and "a5" is Row 4, Col 0.
+//
+// Player 2 switching sides is written "swap" (or "swap-sides"), and swapping pieces is written "swap-pieces".
+// Deciding not to swap isn't part of the standard notation, so hexit writes it as "noswap".
+//
+// A whole position is written row by row from the top, with rows separated by "/".
+// For example, "x----/-o---/-----/-----/-----" has Player 1's stone at a1 and Player 2's stone at b2.
+
+const (
+	swapToken       = "swap"
+	swapSidesToken  = "swap-sides"
+	swapPiecesToken = "swap-pieces"
+	noSwapToken     = "noswap"
+)
+
+const positionRowSeparator = "/"
+
+// FormatLocation formats a board location, such as "c3"
+func FormatLocation(location BoardLocation) string {
+	return fmt.Sprintf("%c%d", 'a'+rune(location.Col), location.Row+1)
+}
+
+// ParseLocation parses a board location such as "c3" on a board with the given dimensions
+func ParseLocation(text string, numRows uint, numCols uint) (error, BoardLocation) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if len(text) < 2 || text[0] < 'a' || text[0] > 'z' {
+		return fmt.Errorf("Invalid location %q", text), BoardLocation{}
+	}
+	col := uint(text[0] - 'a')
+	row, err := strconv.ParseUint(text[1:], 10, 32)
+	if err != nil || row == 0 {
+		return fmt.Errorf("Invalid location %q", text), BoardLocation{}
+	}
+	location := BoardLocation{Row: uint(row) - 1, Col: col}
+	if location.Row >= numRows || location.Col >= numCols {
+		return fmt.Errorf("Location %q is not on the board", text), BoardLocation{}
+	}
+	return nil, location
+}
+
+// FormatMove formats a move from a game's history, such as "c3", "swap" or "swap-pieces"
+func FormatMove(move GameMove) string {
+	switch move.Type {
+	case RegularMove:
+		return FormatLocation(move.Location)
+	case SwitchSidesMove:
+		return swapToken
+	case DoNotSwitchSidesMove:
+		return noSwapToken
+	case SwapPiecesMove:
+		return swapPiecesToken
+	}
+	panic("Invalid move type")
+}
+
+// ParseMove parses a move such as "c3" or "swap" on a board with the given dimensions
+func ParseMove(text string, numRows uint, numCols uint) (error, GameMove) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case swapToken, swapSidesToken:
+		return nil, GameMove{Type: SwitchSidesMove}
+	case swapPiecesToken:
+		return nil, GameMove{Type: SwapPiecesMove}
+	case noSwapToken:
+		return nil, GameMove{Type: DoNotSwitchSidesMove}
+	}
+
+	err, location := ParseLocation(text, numRows, numCols)
+	if err != nil {
+		return err, GameMove{}
+	}
+	return nil, GameMove{Type: RegularMove, Location: location}
+}
+
+// FormatPosition writes a board as a position string, such as "x----/-o---/-----/-----/-----"
+func FormatPosition(board Board) string {
+	rows := make([]string, len(board))
+	for i, row := range board {
+		var text strings.Builder
+		for _, boardSquareValue := range row {
+			text.WriteString(strings.ToLower(formatBoardSquare(boardSquareValue)))
+		}
+		rows[i] = text.String()
+	}
+	return strings.Join(rows, positionRowSeparator)
+}
+
+// ParsePosition parses a position string written by FormatPosition. Empty squares can also be written ".".
+func ParsePosition(text string) (error, Board) {
+	rows := strings.Split(strings.ToLower(strings.TrimSpace(text)), positionRowSeparator)
+	numRows := uint(len(rows))
+	numCols := uint(len(rows[0]))
+	if numRows == 0 || numCols == 0 || numRows > MaxBoardSize || numCols > MaxBoardSize {
+		return fmt.Errorf("Position must have between 1 and %d rows and columns", MaxBoardSize), nil
+	}
+
+	board := NewRectangularBoard(numRows, numCols)
+	for i, row := range rows {
+		if uint(len(row)) != numCols {
+			return fmt.Errorf("Row %d of the position has %d squares instead of %d", i+1, len(row), numCols), nil
+		}
+		for j, symbol := range row {
+			switch symbol {
+			case 'x':
+				board[i][j] = 1
+			case 'o':
+				board[i][j] = 2
+			case '-', '.':
+			default:
+				return fmt.Errorf("Invalid square %q in the position", symbol), nil
+			}
+		}
+	}
+	return nil, board
+}
@@ -0,0 +1,126 @@
+package hexit
+
+import "testing"
+
+func TestFormatLocation(t *testing.T) {
+	if FormatLocation(Move{Row: 2, Col: 2}) != "c3" {
+		t.Error("Expected Row 2, Col 2 to be c3")
+	}
+	if FormatLocation(Move{Row: 4, Col: 0}) != "a5" {
+		t.Error("Expected Row 4, Col 0 to be a5")
+	}
+	if FormatLocation(Move{Row: 18, Col: 18}) != "s19" {
+		t.Error("Expected Row 18, Col 18 to be s19")
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	err, location := ParseLocation("c3", 5, 5)
+	if err != nil || location != (Move{Row: 2, Col: 2}) {
+		t.Error("Expected c3 to be Row 2, Col 2")
+	}
+	err, location = ParseLocation(" E1\n", 5, 5)
+	if err != nil || location != (Move{Row: 0, Col: 4}) {
+		t.Error("Expected E1 to be Row 0, Col 4")
+	}
+	err, location = ParseLocation("k11", 11, 11)
+	if err != nil || location != (Move{Row: 10, Col: 10}) {
+		t.Error("Expected k11 to be Row 10, Col 10")
+	}
+
+	for _, invalidLocation := range []string{"", "c", "3", "c0", "f1", "a6", "c3x", "3c", "0,0"} {
+		err, _ = ParseLocation(invalidLocation, 5, 5)
+		if err == nil {
+			t.Errorf("Expected %q to be invalid on a 5x5 board", invalidLocation)
+		}
+	}
+}
+
+func TestParseLocationOnRectangularBoard(t *testing.T) {
+	err, _ := ParseLocation("e4", 4, 5)
+	if err != nil {
+		t.Error("Expected e4 to be on a board with 4 rows and 5 columns")
+	}
+	err, _ = ParseLocation("d5", 4, 5)
+	if err == nil {
+		t.Error("Expected d5 to be off a board with 4 rows")
+	}
+}
+
+func TestParseAndFormatMove(t *testing.T) {
+	moves := []GameMove{
+		{Type: RegularMove, Location: Move{Row: 1, Col: 3}},
+		{Type: SwitchSidesMove},
+		{Type: DoNotSwitchSidesMove},
+		{Type: SwapPiecesMove},
+	}
+	for _, move := range moves {
+		err, parsedMove := ParseMove(FormatMove(move), 5, 5)
+		if err != nil {
+			t.Error(err.Error())
+		}
+		if parsedMove != move {
+			t.Errorf("Expected %q to parse back into %v", FormatMove(move), move)
+		}
+	}
+
+	err, move := ParseMove("SWAP", 5, 5)
+	if err != nil || move.Type != SwitchSidesMove {
+		t.Error("Expected SWAP to switch sides")
+	}
+	err, move = ParseMove("swap-sides", 5, 5)
+	if err != nil || move.Type != SwitchSidesMove {
+		t.Error("Expected swap-sides to switch sides")
+	}
+}
+
+func TestApplyGameMove(t *testing.T) {
+	game := NewGame()
+	err, move := ParseMove("c3", 5, 5)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, game = ApplyGameMove(game, move)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	// A regular move on move 2 means Player 2 didn't switch sides
+	err, game = ApplyGameMove(game, GameMove{Type: RegularMove, Location: Move{Row: 0, Col: 0}})
+	if err != nil {
+		t.Error(err.Error())
+	}
+	if game.MoveNum != 4 || game.SwitchedSides || game.Board[0][0] != 2 {
+		t.Error("Expected Player 2 to play a1 without switching sides")
+	}
+
+	err, _ = ApplyGameMove(game, GameMove{Type: RegularMove, Location: Move{Row: 2, Col: 2}})
+	if err == nil {
+		t.Error("Should not be able to play on an occupied location")
+	}
+	err, _ = ApplyGameMove(game, GameMove{Type: SwitchSidesMove})
+	if err == nil {
+		t.Error("Should only be able to switch sides on move 2")
+	}
+}
+
+func TestParseAndFormatPosition(t *testing.T) {
+	err, board := ParsePosition("X--/.o-")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	numRows, numCols := GetBoardDimensions(board)
+	if numRows != 2 || numCols != 3 || board[0][0] != 1 || board[1][1] != 2 {
+		t.Error("Expected a 2x3 board with stones at a1 and b2")
+	}
+	if FormatPosition(board) != "x--/-o-" {
+		t.Errorf("Unexpected position %q", FormatPosition(board))
+	}
+
+	for _, invalidPosition := range []string{"", "x--/-o", "x--/-y-", "x--//---"} {
+		err, _ = ParsePosition(invalidPosition)
+		if err == nil {
+			t.Errorf("Expected %q to be invalid", invalidPosition)
+		}
+	}
+}
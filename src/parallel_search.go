@@ -0,0 +1,112 @@
+package hexit
+
+import (
+	"runtime"
+	"sync"
+)
+
+// virtualLoss is how much value a pending visit takes away from each node on its path.
+// While a leaf is being evaluated, the path to it looks like a loss, so that the search tries other paths.
+const virtualLoss = float32(1)
+
+// parallelSearch is the state that the goroutines of DoParallelVisits share
+type parallelSearch struct {
+	tree             *SearchTree
+	evaluatePosition Evaluator
+	// Guards the tree and the fields below
+	mutex sync.Mutex
+	// Number of visits that haven't been started yet
+	numVisitsLeft int
+	// Leaves that a goroutine is evaluating
+	pendingLeaves map[*SearchNode]bool
+}
+
+// addVirtualLoss adds a pending visit to a leaf node and each of its ancestors, with a loss for each of them.
+// A negative number of visits takes them away again.
+func addVirtualLoss(tree *SearchTree, leafNode *SearchNode, numVisits int) {
+	for node := leafNode; node != nil; node = node.parent {
+		node.n = uint32(int(node.n) + numVisits)
+		node.w -= float32(numVisits) * virtualLoss
+		if node.n == 0 {
+			node.q = 0
+		} else {
+			node.q = node.w / float32(node.n)
+		}
+		if isSharingStats(tree) && node.transposition != nil {
+			node.transposition.n = uint32(int(node.transposition.n) + numVisits)
+			node.transposition.w -= float32(numVisits) * virtualLoss
+		}
+	}
+}
+
+// doParallelSearchVisits does visits on one goroutine until there are no visits left
+func doParallelSearchVisits(search *parallelSearch) {
+	search.mutex.Lock()
+	for search.numVisitsLeft > 0 {
+		leafNode, leafGame, leafPosition := selectLeaf(search.tree)
+		if GetPositionWinner(&leafPosition) != 0 {
+			// There's nothing to evaluate, so the visit can be finished right away
+			leafNode.isTerminal = true
+			leafNode.v = 1
+			backUpValue(search.tree, leafNode, leafNode.v)
+			search.numVisitsLeft--
+			continue
+		}
+		if search.pendingLeaves[leafNode] {
+			// Another goroutine is evaluating this leaf. Let it finish, and then select again.
+			search.mutex.Unlock()
+			runtime.Gosched()
+			search.mutex.Lock()
+			continue
+		}
+		search.numVisitsLeft--
+		leafBoard := GetPositionBoard(&leafPosition)
+		if expandFromTransposition(leafNode, leafBoard) {
+			backUpValue(search.tree, leafNode, leafNode.v)
+			continue
+		}
+		search.pendingLeaves[leafNode] = true
+		addVirtualLoss(search.tree, leafNode, 1)
+
+		// Evaluate without holding the lock, since evaluating is the slow part of a visit
+		search.mutex.Unlock()
+		valueEstimate, policyEstimates := search.evaluatePosition(leafBoard, leafGame.CurrentPlayer)
+		search.mutex.Lock()
+
+		addVirtualLoss(search.tree, leafNode, -1)
+		delete(search.pendingLeaves, leafNode)
+		storeTranspositionEvaluation(leafNode, valueEstimate, policyEstimates)
+		expandNode(leafNode, leafBoard, valueEstimate, policyEstimates)
+		backUpValue(search.tree, leafNode, leafNode.v)
+	}
+	search.mutex.Unlock()
+}
+
+// DoParallelVisits performs numVisits iterations of tree search, on numThreads goroutines that share the tree.
+// Virtual loss steers the goroutines onto different paths through the tree.
+// The evaluator must be safe to call from several goroutines at once.
+// With 1 thread, it's the same as calling DoVisit numVisits times.
+func DoParallelVisits(tree *SearchTree, evaluatePosition Evaluator, numVisits int, numThreads int) {
+	if numThreads <= 1 {
+		for i := 0; i < numVisits; i++ {
+			DoVisit(tree, evaluatePosition)
+		}
+		return
+	}
+
+	search := parallelSearch{
+		tree:             tree,
+		evaluatePosition: evaluatePosition,
+		numVisitsLeft:    numVisits,
+		pendingLeaves:    make(map[*SearchNode]bool),
+	}
+	var waitGroup sync.WaitGroup
+	for i := 0; i < numThreads; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			doParallelSearchVisits(&search)
+		}()
+	}
+	waitGroup.Wait()
+}
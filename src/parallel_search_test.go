@@ -0,0 +1,76 @@
+package hexit
+
+import (
+	"math"
+	"testing"
+)
+
+// checkVisitCounts checks that a node's visits add up, with no virtual losses left behind
+func checkVisitCounts(t *testing.T, node *SearchNode, isRoot bool) {
+	if node.n > 0 && math.Abs(float64(node.q-node.w/float32(node.n))) > 1e-4 {
+		t.Errorf("Expected Q to be W/N, got Q = %f, W = %f, N = %d", node.q, node.w, node.n)
+	}
+	if node.firstChild == nil {
+		return
+	}
+	totalChildVisits := uint32(0)
+	for childNode := node.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		totalChildVisits += childNode.n
+		checkVisitCounts(t, childNode, false)
+	}
+	// Each node was visited once to expand it, except for the root, which was expanded when the tree was created
+	expectedVisits := totalChildVisits + 1
+	if isRoot {
+		expectedVisits = totalChildVisits
+	}
+	if node.n != expectedVisits {
+		t.Errorf("Expected %d visits, got %d", expectedVisits, node.n)
+	}
+}
+
+func TestDoParallelVisits(t *testing.T) {
+	game := NewGame()
+	// Player 1 can win by playing at (4, 0)
+	game.Board = [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+	}
+
+	tree := NewSearchTree(EvaluatePositionUniformly, game)
+	DoParallelVisits(&tree, EvaluatePositionUniformly, 1000, 4)
+	if tree.rootNode.n != 1000 {
+		t.Errorf("Expected 1000 visits, got %d", tree.rootNode.n)
+	}
+	checkVisitCounts(t, tree.rootNode, true)
+
+	bestMove := GetBestMove(&tree)
+	if bestMove.Row != 4 || bestMove.Col != 0 {
+		t.Error("Failed to find the winning move")
+	}
+}
+
+func TestDoParallelVisitsMatchesDoVisit(t *testing.T) {
+	err, board := ParsePosition("--x--/-o---/--x--/---o-/-----")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, game := NewGameFromBoard(board, SwapSidesRule)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	sequentialTree := NewSearchTree(EvaluatePositionRandomly, game)
+	DoParallelVisits(&sequentialTree, EvaluatePositionRandomly, 4000, 1)
+	parallelTree := NewSearchTree(EvaluatePositionRandomly, game)
+	DoParallelVisits(&parallelTree, EvaluatePositionRandomly, 4000, 8)
+	checkVisitCounts(t, parallelTree.rootNode, true)
+
+	sequentialValue := GetExpectedValueOfGame(&sequentialTree)
+	parallelValue := GetExpectedValueOfGame(&parallelTree)
+	if math.Abs(float64(sequentialValue-parallelValue)) > 0.2 {
+		t.Errorf("Expected similar values from sequential and parallel search, got %f and %f", sequentialValue, parallelValue)
+	}
+}
@@ -0,0 +1,250 @@
+package hexit
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// A bitboard has one bit per board square, numbered row by row from the top left.
+const bitboardWords = (MaxBoardSize*MaxBoardSize + 63) / 64
+
+type bitboard [bitboardWords]uint64
+
+func (b *bitboard) set(square uint) {
+	b[square/64] |= 1 << (square % 64)
+}
+
+func (b *bitboard) has(square uint) bool {
+	return b[square/64]&(1<<(square%64)) != 0
+}
+
+// Besides the board squares, the union-find structure has one node for each side of the board.
+// A player has won once their two sides are in the same set.
+const (
+	topSide = iota
+	bottomSide
+	leftSide
+	rightSide
+	numSides
+)
+
+// boardGeometry holds everything about a board that only depends on its dimensions
+type boardGeometry struct {
+	numRows uint
+	numCols uint
+	// neighborMasks[square] has a bit set for every square adjacent to square
+	neighborMasks []bitboard
+}
+
+var (
+	boardGeometriesMutex sync.Mutex
+	boardGeometries      = map[[2]uint]*boardGeometry{}
+)
+
+// getBoardGeometry returns the precomputed geometry for a board size, computing it the first time it's needed
+func getBoardGeometry(numRows uint, numCols uint) *boardGeometry {
+	boardGeometriesMutex.Lock()
+	defer boardGeometriesMutex.Unlock()
+
+	key := [2]uint{numRows, numCols}
+	if geometry, ok := boardGeometries[key]; ok {
+		return geometry
+	}
+
+	if numRows == 0 || numRows > MaxBoardSize || numCols == 0 || numCols > MaxBoardSize {
+		panic("Invalid board size")
+	}
+
+	geometry := &boardGeometry{
+		numRows:       numRows,
+		numCols:       numCols,
+		neighborMasks: make([]bitboard, numRows*numCols),
+	}
+	for row := uint(0); row < numRows; row++ {
+		for col := uint(0); col < numCols; col++ {
+			square := row*numCols + col
+			for _, adjacentLocation := range getAdjacentLocations(BoardLocation{Row: row, Col: col}, numRows, numCols) {
+				geometry.neighborMasks[square].set(adjacentLocation.Row*numCols + adjacentLocation.Col)
+			}
+		}
+	}
+	boardGeometries[key] = geometry
+	return geometry
+}
+
+// Position is a compact representation of a board that can detect wins in near-constant time.
+// Stones are stored in bitboards, and connected groups are tracked incrementally with union-find.
+// Use GetPositionBoard to view a Position as a Board, and NewPositionFromBoard to go the other way.
+// Evaluators, renderers and game records all work with boards, so Game stores a Board, and keeps a Position next to it for GetGameWinner.
+// SearchTree keeps its own Position, which the search plays moves on in place.
+type Position struct {
+	geometry *boardGeometry
+	// stones[player-1] has a bit set for every square occupied by that player
+	stones [2]bitboard
+	// Union-find over the board squares, followed by one node per side of the board
+	parent []uint16
+	rank   []uint8
+	winner byte
+}
+
+// NewPosition creates an empty position
+func NewPosition(numRows uint, numCols uint) Position {
+	geometry := getBoardGeometry(numRows, numCols)
+	numNodes := numRows*numCols + numSides
+	position := Position{
+		geometry: geometry,
+		parent:   make([]uint16, numNodes),
+		rank:     make([]uint8, numNodes),
+	}
+	for i := range position.parent {
+		position.parent[i] = uint16(i)
+	}
+	return position
+}
+
+// NewPositionFromBoard creates a position with the same stones as a board
+func NewPositionFromBoard(board Board) Position {
+	numRows, numCols := GetBoardDimensions(board)
+	position := NewPosition(numRows, numCols)
+	for row := uint(0); row < numRows; row++ {
+		for col := uint(0); col < numCols; col++ {
+			if board[row][col] != 0 {
+				placeStone(&position, board[row][col], row, col)
+			}
+		}
+	}
+	// Stones were placed in an arbitrary order, so check both players the same way GetWinner does
+	position.winner = 0
+	if isConnected(&position, 1) {
+		position.winner = 1
+	} else if isConnected(&position, 2) {
+		position.winner = 2
+	}
+	return position
+}
+
+// CopyPosition returns a copy of a position that can be modified independently
+func CopyPosition(position *Position) Position {
+	newPosition := *position
+	newPosition.parent = make([]uint16, len(position.parent))
+	copy(newPosition.parent, position.parent)
+	newPosition.rank = make([]uint8, len(position.rank))
+	copy(newPosition.rank, position.rank)
+	return newPosition
+}
+
+// GetPositionDimensions returns the number of rows and columns of a position
+func GetPositionDimensions(position *Position) (uint, uint) {
+	return position.geometry.numRows, position.geometry.numCols
+}
+
+// GetPositionSquare returns the player occupying a square, or 0 if it's empty
+func GetPositionSquare(position *Position, row uint, col uint) byte {
+	square := row*position.geometry.numCols + col
+	if position.stones[0].has(square) {
+		return 1
+	} else if position.stones[1].has(square) {
+		return 2
+	}
+	return 0
+}
+
+// GetPositionBoard returns the position as a Board
+func GetPositionBoard(position *Position) Board {
+	numRows, numCols := GetPositionDimensions(position)
+	board := NewRectangularBoard(numRows, numCols)
+	for row := uint(0); row < numRows; row++ {
+		for col := uint(0); col < numCols; col++ {
+			board[row][col] = GetPositionSquare(position, row, col)
+		}
+	}
+	return board
+}
+
+// PlayPositionMove plays a move in place, updating the winner.
+func PlayPositionMove(position *Position, player byte, row uint, col uint) {
+	if row >= position.geometry.numRows || col >= position.geometry.numCols {
+		panic("Location is off the board")
+	}
+	if GetPositionSquare(position, row, col) != 0 {
+		panic("Location is already occupied")
+	}
+	placeStone(position, player, row, col)
+	// Only the player who just moved can have completed a connection
+	if position.winner == 0 && isConnected(position, player) {
+		position.winner = player
+	}
+}
+
+// GetPositionWinner returns the player that won the game, or 0 if the game is still in progress.
+func GetPositionWinner(position *Position) byte {
+	return position.winner
+}
+
+func placeStone(position *Position, player byte, row uint, col uint) {
+	geometry := position.geometry
+	square := row*geometry.numCols + col
+	position.stones[player-1].set(square)
+
+	// Join the new stone with adjacent stones of the same color
+	for i, neighborWord := range geometry.neighborMasks[square] {
+		word := neighborWord & position.stones[player-1][i]
+		for word != 0 {
+			bit := uint(bits.TrailingZeros64(word))
+			union(position, square, uint(i)*64+bit)
+			word &= word - 1
+		}
+	}
+
+	// Join the new stone with the sides of the board it touches
+	numSquares := geometry.numRows * geometry.numCols
+	if player == 1 {
+		if row == 0 {
+			union(position, square, numSquares+topSide)
+		}
+		if row == geometry.numRows-1 {
+			union(position, square, numSquares+bottomSide)
+		}
+	} else {
+		if col == 0 {
+			union(position, square, numSquares+leftSide)
+		}
+		if col == geometry.numCols-1 {
+			union(position, square, numSquares+rightSide)
+		}
+	}
+}
+
+// isConnected returns true if the player's two sides of the board are connected
+func isConnected(position *Position, player byte) bool {
+	numSquares := position.geometry.numRows * position.geometry.numCols
+	if player == 1 {
+		return find(position, numSquares+topSide) == find(position, numSquares+bottomSide)
+	}
+	return find(position, numSquares+leftSide) == find(position, numSquares+rightSide)
+}
+
+func find(position *Position, node uint) uint {
+	// Path halving
+	for uint(position.parent[node]) != node {
+		position.parent[node] = position.parent[position.parent[node]]
+		node = uint(position.parent[node])
+	}
+	return node
+}
+
+func union(position *Position, node1 uint, node2 uint) {
+	root1 := find(position, node1)
+	root2 := find(position, node2)
+	if root1 == root2 {
+		return
+	}
+	// Union by rank
+	if position.rank[root1] < position.rank[root2] {
+		root1, root2 = root2, root1
+	}
+	position.parent[root2] = uint16(root1)
+	if position.rank[root1] == position.rank[root2] {
+		position.rank[root1]++
+	}
+}
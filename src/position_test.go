@@ -0,0 +1,101 @@
+package hexit
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNewPositionIsEmpty(t *testing.T) {
+	position := NewPosition(5, 5)
+	if GetPositionSquare(&position, 0, 0) != 0 {
+		t.Error("Position should be empty")
+	}
+	if GetPositionWinner(&position) != 0 {
+		t.Error("The game just started, there's no winner yet!")
+	}
+}
+
+func TestPlayPositionMove(t *testing.T) {
+	position := NewPosition(5, 5)
+	PlayPositionMove(&position, 2, 3, 4)
+	if GetPositionSquare(&position, 3, 4) != 2 {
+		t.Error("Expected Player 2 to fill a grid location")
+	}
+	board := GetPositionBoard(&position)
+	if board[3][4] != 2 {
+		t.Error("Expected the board view to show Player 2's stone")
+	}
+}
+
+func TestCopyPosition(t *testing.T) {
+	position := NewPosition(5, 5)
+	PlayPositionMove(&position, 1, 0, 0)
+	copiedPosition := CopyPosition(&position)
+	for row := uint(1); row < 5; row++ {
+		PlayPositionMove(&copiedPosition, 1, row, 0)
+	}
+	if GetPositionWinner(&copiedPosition) != 1 {
+		t.Error("Expected Player 1 to win on the copied position")
+	}
+	if GetPositionWinner(&position) != 0 || GetPositionSquare(&position, 1, 0) != 0 {
+		t.Error("Playing on a copy should not change the original position")
+	}
+}
+
+/*
+ X - - - -
+  X - X X X
+   X X - - X
+    - - - X -
+     - - - - -
+*/
+func TestPositionPlayerOneWinsWithWindingPath(t *testing.T) {
+	position := NewPositionFromBoard([][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 1, 1, 1},
+		[]byte{1, 1, 0, 0, 1},
+		[]byte{0, 0, 0, 1, 0},
+		[]byte{0, 0, 0, 0, 0},
+	})
+	if GetPositionWinner(&position) != 0 {
+		t.Error("Player 1 hasn't won yet!")
+	}
+	PlayPositionMove(&position, 1, 4, 3)
+	if GetPositionWinner(&position) != 1 {
+		t.Error("Expected Player 1 to be the winner")
+	}
+}
+
+// The position should always agree with GetWinner, including on rectangular boards
+func TestPositionWinnerMatchesGetWinner(t *testing.T) {
+	random := rand.New(rand.NewSource(0))
+	boardDimensions := [][2]uint{{1, 1}, {2, 3}, {5, 5}, {4, 5}, {7, 6}, {11, 11}, {19, 19}}
+	for _, dimensions := range boardDimensions {
+		numRows, numCols := dimensions[0], dimensions[1]
+		for gameNum := 0; gameNum < 20; gameNum++ {
+			board := NewRectangularBoard(numRows, numCols)
+			position := NewPosition(numRows, numCols)
+			player := byte(1)
+			for _, square := range random.Perm(int(numRows * numCols)) {
+				row, col := uint(square)/numCols, uint(square)%numCols
+				board = PlayMove(board, player, row, col)
+				PlayPositionMove(&position, player, row, col)
+				if GetPositionWinner(&position) != GetWinner(board) {
+					t.Fatalf(
+						"On a %dx%d board, expected winner %d but the position has winner %d",
+						numRows, numCols, GetWinner(board), GetPositionWinner(&position),
+					)
+				}
+				if GetWinner(board) != 0 {
+					break
+				}
+				player = OtherPlayer(player)
+			}
+
+			positionFromBoard := NewPositionFromBoard(board)
+			if GetPositionWinner(&positionFromBoard) != GetWinner(board) {
+				t.Fatalf("Expected NewPositionFromBoard to find winner %d", GetWinner(board))
+			}
+		}
+	}
+}
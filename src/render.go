@@ -0,0 +1,154 @@
+package hexit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderOptions controls how RenderBoard draws a board.
+// The zero value draws a bare board of X, O and -.
+type RenderOptions struct {
+	// Color the stones with ANSI escape codes: red for Player 1 and blue for Player 2.
+	// With Labels, the labels are colored the same way to show which player connects which edges.
+	Color bool
+	// Show column letters along Player 1's edges (top and bottom) and row numbers along Player 2's edges (left and right),
+	// in the same notation as FormatLocation
+	Labels bool
+	// If set, the stone played here is marked with parentheses
+	LastMove *BoardLocation
+	// If the game is over, mark the stones of the winning chain: with Color they're highlighted, otherwise they're shown as "*"
+	WinningPath bool
+}
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiRed     = "\x1b[31m"
+	ansiBlue    = "\x1b[34m"
+	ansiReverse = "\x1b[7m"
+)
+
+// winningPathSymbol marks the stones of the winning chain when there's no color
+const winningPathSymbol = "*"
+
+func formatBoardSquare(boardSquareValue byte) string {
+	if boardSquareValue == 1 {
+		return "X"
+	} else if boardSquareValue == 2 {
+		return "O"
+	} else {
+		return "-"
+	}
+}
+
+// GetPlayerSymbol gets the symbol of the stones that the player places, as RenderBoard draws them
+func GetPlayerSymbol(player byte) string {
+	return formatBoardSquare(player)
+}
+
+func getPlayerColor(player byte) string {
+	if player == 1 {
+		return ansiRed
+	}
+	return ansiBlue
+}
+
+// colorText colors text for a player, if colors are enabled
+func colorText(text string, player byte, options RenderOptions) string {
+	if !options.Color {
+		return text
+	}
+	return getPlayerColor(player) + text + ansiReset
+}
+
+func renderBoardSquare(boardSquareValue byte, isOnWinningPath bool, options RenderOptions) string {
+	symbol := formatBoardSquare(boardSquareValue)
+	if boardSquareValue == 0 {
+		return symbol
+	}
+	if isOnWinningPath {
+		if !options.Color {
+			return winningPathSymbol
+		}
+		return ansiReverse + getPlayerColor(boardSquareValue) + symbol + ansiReset
+	}
+	return colorText(symbol, boardSquareValue, options)
+}
+
+// renderColumnLabels draws the column letters as if they were a row of the board with the given index
+func renderColumnLabels(rowIndex int, rowLabelWidth int, numCols uint, options RenderOptions) string {
+	var line strings.Builder
+	line.WriteString(strings.Repeat(" ", rowIndex+rowLabelWidth+1))
+	for j := uint(0); j < numCols; j++ {
+		if j != 0 {
+			line.WriteString(" ")
+		}
+		line.WriteString(colorText(fmt.Sprintf("%c", 'a'+rune(j)), 1, options))
+	}
+	return line.String()
+}
+
+// RenderBoard draws the board as text, with each row indented to show the hex grid
+func RenderBoard(board Board, options RenderOptions) string {
+	isOnWinningPath := make(map[BoardLocation]bool)
+	if options.WinningPath {
+		_, winningPath := GetWinningPath(board)
+		for _, location := range winningPath {
+			isOnWinningPath[location] = true
+		}
+	}
+	isLastMove := func(i uint, j uint) bool {
+		return options.LastMove != nil && *options.LastMove == BoardLocation{Row: i, Col: j}
+	}
+
+	numRows, numCols := GetBoardDimensions(board)
+	rowLabelWidth := 0
+	if options.Labels {
+		rowLabelWidth = len(fmt.Sprint(numRows))
+	}
+
+	lines := make([]string, 0, numRows+2)
+	if options.Labels {
+		lines = append(lines, renderColumnLabels(-1, rowLabelWidth, numCols, options))
+	}
+	for i := uint(0); i < numRows; i++ {
+		var line strings.Builder
+		indent := int(i)
+		if !options.Labels && isLastMove(i, 0) && indent > 0 {
+			// Make room for the last move marker
+			indent--
+		}
+		line.WriteString(strings.Repeat(" ", indent))
+		rowLabel := fmt.Sprint(i + 1)
+		if options.Labels {
+			line.WriteString(strings.Repeat(" ", rowLabelWidth-len(rowLabel)))
+			line.WriteString(colorText(rowLabel, 2, options))
+		}
+
+		// Each square is preceded by a separator, which is "(" before the last move and ")" after it
+		for j := uint(0); j < numCols; j++ {
+			if isLastMove(i, j) {
+				line.WriteString("(")
+			} else if j != 0 && isLastMove(i, j-1) {
+				line.WriteString(")")
+			} else if j != 0 || options.Labels {
+				line.WriteString(" ")
+			}
+			line.WriteString(renderBoardSquare(board[i][j], isOnWinningPath[BoardLocation{Row: i, Col: j}], options))
+		}
+		if isLastMove(i, numCols-1) {
+			line.WriteString(")")
+		} else if options.Labels {
+			line.WriteString(" ")
+		}
+
+		if options.Labels {
+			line.WriteString(colorText(rowLabel, 2, options))
+		}
+		lines = append(lines, line.String())
+	}
+	if options.Labels {
+		lines = append(lines, renderColumnLabels(int(numRows), rowLabelWidth, numCols, options))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,98 @@
+package hexit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderBoard(t *testing.T) {
+	board := [][]byte{
+		[]byte{1, 0, 0},
+		[]byte{0, 2, 0},
+		[]byte{0, 0, 0},
+	}
+	expectedText := "" +
+		"X - -\n" +
+		" - O -\n" +
+		"  - - -\n"
+	text := RenderBoard(board, RenderOptions{})
+	if text != expectedText {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedText, text)
+	}
+}
+
+func TestRenderBoardWithLabels(t *testing.T) {
+	board := [][]byte{
+		[]byte{1, 0, 0},
+		[]byte{0, 2, 0},
+	}
+	lastMove := BoardLocation{Row: 1, Col: 1}
+	expectedText := "" +
+		" a b c\n" +
+		"1 X - - 1\n" +
+		" 2 -(O)- 2\n" +
+		"    a b c\n"
+	text := RenderBoard(board, RenderOptions{Labels: true, LastMove: &lastMove})
+	if text != expectedText {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedText, text)
+	}
+}
+
+func TestRenderBoardWithTwoDigitRowLabels(t *testing.T) {
+	text := RenderBoard(NewRectangularBoard(10, 2), RenderOptions{Labels: true})
+	lines := strings.Split(text, "\n")
+	if lines[1] != " 1 - - 1" || lines[10] != "         10 - - 10" {
+		t.Errorf("Expected row labels to be aligned, got:\n%s", text)
+	}
+}
+
+func TestRenderBoardMarksLastMoveAtEdges(t *testing.T) {
+	board := [][]byte{
+		[]byte{0, 0, 0},
+		[]byte{1, 0, 2},
+	}
+	lastMove := BoardLocation{Row: 1, Col: 0}
+	if RenderBoard(board, RenderOptions{LastMove: &lastMove}) != "- - -\n(X)- O\n" {
+		t.Error("Expected the last move marker to replace the indentation")
+	}
+	lastMove = BoardLocation{Row: 1, Col: 2}
+	if RenderBoard(board, RenderOptions{LastMove: &lastMove}) != "- - -\n X -(O)\n" {
+		t.Error("Expected the last move marker at the end of the row")
+	}
+}
+
+func TestRenderBoardMarksWinningPath(t *testing.T) {
+	board := [][]byte{
+		[]byte{1, 0, 2},
+		[]byte{1, 1, 0},
+		[]byte{1, 2, 0},
+	}
+	expectedText := "* - O\n * X -\n  * O -\n"
+	text := RenderBoard(board, RenderOptions{WinningPath: true})
+	if text != expectedText {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expectedText, text)
+	}
+	if RenderBoard(board, RenderOptions{}) != "X - O\n X X -\n  X O -\n" {
+		t.Error("The winning chain should only be marked if requested")
+	}
+}
+
+func TestRenderBoardWithColor(t *testing.T) {
+	board := [][]byte{
+		[]byte{1, 2},
+		[]byte{1, 0},
+	}
+	text := RenderBoard(board, RenderOptions{Color: true, Labels: true, WinningPath: true})
+	if !strings.Contains(text, ansiReverse+ansiRed+"X"+ansiReset) {
+		t.Error("Expected the winning chain to be highlighted")
+	}
+	if !strings.Contains(text, ansiBlue+"O"+ansiReset) {
+		t.Error("Expected Player 2's stones to be blue")
+	}
+	if !strings.Contains(text, ansiRed+"a"+ansiReset) || !strings.Contains(text, ansiBlue+"1"+ansiReset) {
+		t.Error("Expected the labels to be colored to show the goal edges")
+	}
+	if strings.Contains(RenderBoard(board, RenderOptions{}), "\x1b") {
+		t.Error("Expected no escape codes without color")
+	}
+}
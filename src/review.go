@@ -0,0 +1,198 @@
+package hexit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReviewOptions configures ReviewGame
+type ReviewOptions struct {
+	// Number of search visits for each position
+	NumVisits int
+	// Number of goroutines that search each position
+	NumThreads int
+	// A move is a blunder if it loses at least this much value for the player who made it
+	BlunderThreshold float32
+	// A player is winning if their value is at least this much
+	WinThreshold float32
+}
+
+// DefaultReviewOptions are reasonable options for reviewing a game
+var DefaultReviewOptions = ReviewOptions{NumVisits: 1000, NumThreads: 1, BlunderThreshold: 0.4, WinThreshold: 0.9}
+
+// MoveReview compares one move of a game with the move that the search prefers.
+// Values are from the point of view of the player who made the move, from -1 (losing) to +1 (winning).
+type MoveReview struct {
+	// Move number, counting moves the way they're written down
+	MoveNum int
+	// Original player who made the move, ignoring side-switching
+	Player byte
+	// The move that was played. When Player 2 decided not to swap, it's the stone they played instead.
+	Move GameMove
+	// The move that the search prefers
+	BestMove GameMove
+	// Value of the position before the move
+	ValueBefore float32
+	// Value of the position after the move
+	ValueAfter float32
+	// How much value the move lost
+	ValueDrop float32
+	// Whether the move lost a lot of value
+	Blunder bool
+	// Whether the player was winning before the move, but not after it
+	MissedWin bool
+}
+
+// positionReview is what the search found out about one of the positions of a game
+type positionReview struct {
+	// Expected value of the game, as returned by GetExpectedValueOfGame
+	expectedValue float32
+	bestMove      GameMove
+}
+
+// reviewPosition searches a position, unless the game is already over
+func reviewPosition(game Game, evaluatePosition Evaluator, options ReviewOptions) positionReview {
+	switch GetGameWinner(game) {
+	case 1:
+		return positionReview{expectedValue: 1}
+	case 2:
+		return positionReview{expectedValue: -1}
+	}
+
+	tree := NewSearchTree(evaluatePosition, game)
+	DoParallelVisits(&tree, evaluatePosition, options.NumVisits, options.NumThreads)
+	review := positionReview{expectedValue: GetExpectedValueOfGame(&tree)}
+	if CanSwap(game) && ShouldSwitchSides(&tree) {
+		review.bestMove = GetSwapMove(game)
+	} else {
+		review.bestMove = GameMove{Type: RegularMove, Location: GetBestMove(&tree)}
+	}
+	return review
+}
+
+// getValueForPlayer converts an expected value of the game to the point of view of an original player
+func getValueForPlayer(expectedValue float32, game Game, player byte) float32 {
+	color := player
+	if game.SwitchedSides {
+		color = OtherPlayer(player)
+	}
+	if color == 1 {
+		return expectedValue
+	}
+	return -expectedValue
+}
+
+// ReviewGame searches every position of a game, to compare each move that was played with the move the search prefers
+func ReviewGame(game Game, evaluatePosition Evaluator, options ReviewOptions) []MoveReview {
+	playedMoves := GetPlayedMoves(game)
+	err, position := ReplayTo(game, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	reviews := make([]MoveReview, 0)
+	// The position after each move is the position before the next one, so each position is only searched once
+	positionAfter := reviewPosition(position, evaluatePosition, options)
+	for i := 0; i < len(playedMoves); i++ {
+		positionBefore := positionAfter
+		gameBefore := position
+		move := playedMoves[i]
+		if move.Type == DoNotSwitchSidesMove {
+			// Review the decision not to swap along with the stone that Player 2 played instead
+			if i+1 == len(playedMoves) {
+				break
+			}
+			err, position = Redo(position)
+			if err != nil {
+				panic(err)
+			}
+			i++
+			move = playedMoves[i]
+		}
+		err, position = Redo(position)
+		if err != nil {
+			panic(err)
+		}
+		positionAfter = reviewPosition(position, evaluatePosition, options)
+
+		player := GetOriginalPlayer(gameBefore)
+		review := MoveReview{
+			MoveNum:     len(reviews) + 1,
+			Player:      player,
+			Move:        move,
+			BestMove:    positionBefore.bestMove,
+			ValueBefore: getValueForPlayer(positionBefore.expectedValue, gameBefore, player),
+			ValueAfter:  getValueForPlayer(positionAfter.expectedValue, position, player),
+		}
+		review.ValueDrop = review.ValueBefore - review.ValueAfter
+		if review.Move != review.BestMove {
+			review.Blunder = review.ValueDrop >= options.BlunderThreshold
+			review.MissedWin = review.ValueBefore >= options.WinThreshold && review.ValueAfter < options.WinThreshold
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews
+}
+
+// FormatMoveReview describes a move review in a sentence or two, such as for a comment in a game record
+func FormatMoveReview(review MoveReview) string {
+	values := fmt.Sprintf("Value %+.2f before, %+.2f after.", review.ValueBefore, review.ValueAfter)
+	if review.Move == review.BestMove {
+		return values + " This is the engine's choice."
+	}
+	description := fmt.Sprintf("%s The engine prefers %s.", values, FormatMove(review.BestMove))
+	if review.MissedWin {
+		return "Missed win! " + description
+	}
+	if review.Blunder {
+		return "Blunder! " + description
+	}
+	return description
+}
+
+// FormatReview formats a table of the move reviews of a game, with a summary of the blunders and missed wins
+func FormatReview(reviews []MoveReview) string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "%4s  %-6s  %-11s  %-11s  %6s  %6s  %6s\n", "Move", "Player", "Played", "Best", "Before", "After", "Drop")
+	numBlunders := []int{0, 0, 0}
+	numMissedWins := []int{0, 0, 0}
+	for _, review := range reviews {
+		note := ""
+		if review.MissedWin {
+			note = "  missed win"
+			numMissedWins[review.Player]++
+		} else if review.Blunder {
+			note = "  blunder"
+		}
+		if review.Blunder {
+			numBlunders[review.Player]++
+		}
+		fmt.Fprintf(
+			&report,
+			"%4d  %-6d  %-11s  %-11s  %+6.2f  %+6.2f  %+6.2f%s\n",
+			review.MoveNum,
+			review.Player,
+			FormatMove(review.Move),
+			FormatMove(review.BestMove),
+			review.ValueBefore,
+			review.ValueAfter,
+			review.ValueDrop,
+			note,
+		)
+	}
+	report.WriteString("\n")
+	for player := byte(1); player <= 2; player++ {
+		fmt.Fprintf(&report, "Player %d: %d blunders, %d missed wins\n", player, numBlunders[player], numMissedWins[player])
+	}
+	return report.String()
+}
+
+// AnnotateGameRecord adds the move reviews of a game to its record, as comments on the moves
+func AnnotateGameRecord(record GameRecord, reviews []MoveReview) GameRecord {
+	annotatedRecord := record
+	annotatedRecord.MoveComments = make([]string, len(reviews))
+	for i, review := range reviews {
+		annotatedRecord.MoveComments[i] = FormatMoveReview(review)
+	}
+	return annotatedRecord
+}
@@ -0,0 +1,112 @@
+package hexit
+
+import "testing"
+
+func playMoves(t *testing.T, moveTexts []string) Game {
+	var err error
+	game := NewGame()
+	for _, moveText := range moveTexts {
+		var move GameMove
+		err, move = ParseMove(moveText, 5, 5)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		err, game = ApplyGameMove(game, move)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	return game
+}
+
+func TestReviewGame(t *testing.T) {
+	options := ReviewOptions{NumVisits: 500, BlunderThreshold: 0.4, WinThreshold: 0.9}
+	openingMoves := []string{"a1", "b1", "a2", "b2", "a3", "b3", "a4", "b4"}
+	winningMove := GameMove{Type: RegularMove, Location: Move{Row: 4, Col: 0}}
+
+	// Player 1 can win with a5, but plays e1 instead
+	game := playMoves(t, append(openingMoves, "e1"))
+	reviews := ReviewGame(game, EvaluatePositionUniformly, options)
+	if len(reviews) != 9 {
+		t.Fatalf("Expected 9 move reviews, got %d", len(reviews))
+	}
+	for i, review := range reviews {
+		if review.MoveNum != i+1 {
+			t.Errorf("Expected review %d to be of move %d, got %d", i, i+1, review.MoveNum)
+		}
+		if review.Player != byte(i%2+1) {
+			t.Errorf("Expected move %d to be made by Player %d", i+1, i%2+1)
+		}
+	}
+	if reviews[1].Move != (GameMove{Type: RegularMove, Location: Move{Row: 0, Col: 1}}) {
+		t.Errorf("Expected Player 2's decision not to swap to be reviewed along with b1, got %s", FormatMove(reviews[1].Move))
+	}
+	missedWin := reviews[8]
+	if missedWin.BestMove != winningMove {
+		t.Errorf("Expected the engine to prefer a5, got %s", FormatMove(missedWin.BestMove))
+	}
+	if !missedWin.MissedWin || !missedWin.Blunder {
+		t.Errorf("Expected e1 to be flagged as a missed win and a blunder, got %+v", missedWin)
+	}
+
+	// Player 1 plays the winning move
+	game = playMoves(t, append(openingMoves, "a5"))
+	reviews = ReviewGame(game, EvaluatePositionUniformly, options)
+	win := reviews[8]
+	if win.Move != win.BestMove || win.Blunder || win.MissedWin {
+		t.Errorf("Expected a5 not to be flagged, got %+v", win)
+	}
+	if win.ValueAfter != 1 {
+		t.Errorf("Expected Player 1 to have won, got a value of %f", win.ValueAfter)
+	}
+}
+
+func TestReviewGameAfterSwitchingSides(t *testing.T) {
+	game := playMoves(t, []string{"c3", "swap", "b2"})
+	reviews := ReviewGame(game, EvaluatePositionUniformly, ReviewOptions{NumVisits: 100, BlunderThreshold: 2, WinThreshold: 2})
+	if len(reviews) != 3 {
+		t.Fatalf("Expected 3 move reviews, got %d", len(reviews))
+	}
+	if reviews[1].Move.Type != SwitchSidesMove || reviews[1].Player != 2 {
+		t.Error("Expected Player 2 to have switched sides on move 2")
+	}
+	// After switching sides, Player 1 plays O
+	if reviews[2].Player != 1 {
+		t.Errorf("Expected Player 1 to make move 3, got Player %d", reviews[2].Player)
+	}
+	if reviews[1].ValueAfter != -reviews[2].ValueBefore {
+		t.Errorf("Expected the players to disagree on the value after switching sides, got %f and %f", reviews[1].ValueAfter, reviews[2].ValueBefore)
+	}
+}
+
+func TestAnnotateGameRecord(t *testing.T) {
+	game := playMoves(t, []string{"a1", "b1", "a2", "b2", "a3", "b3", "a4", "b4", "e1"})
+	reviews := []MoveReview{
+		{MoveNum: 1, Player: 1, Move: GameMove{Type: RegularMove}, BestMove: GameMove{Type: RegularMove}},
+		{
+			MoveNum:     2,
+			Player:      2,
+			Move:        GameMove{Type: RegularMove, Location: Move{Row: 0, Col: 1}},
+			BestMove:    GameMove{Type: SwitchSidesMove},
+			ValueBefore: 0.5,
+			ValueAfter:  -0.25,
+			ValueDrop:   0.75,
+			Blunder:     true,
+		},
+	}
+	record := AnnotateGameRecord(NewGameRecord(game, "", ""), reviews)
+	if len(record.MoveComments) != 2 {
+		t.Fatalf("Expected 2 move comments, got %d", len(record.MoveComments))
+	}
+	expectedComment := "Blunder! Value +0.50 before, -0.25 after. The engine prefers swap."
+	if record.MoveComments[1] != expectedComment {
+		t.Errorf("Expected %q, got %q", expectedComment, record.MoveComments[1])
+	}
+	err, readRecord := ReadSGF(WriteSGF(record))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(readRecord.MoveComments) != 2 || readRecord.MoveComments[1] != expectedComment {
+		t.Error("Expected the comments to be written to the game record")
+	}
+}
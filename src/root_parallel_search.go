@@ -0,0 +1,100 @@
+package hexit
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// MergeSearchTrees merges the root statistics of several search trees of the same game into a new tree,
+// so that GetBestMove, GetMoveWithTemperatureOne, ShouldSwitchSides and GetExpectedValueOfGame can be used on it.
+// For each move from the root, the visits and total values of the trees are added up, and the policy estimates are averaged.
+// Only the root's children are merged: they have no children of their own in the new tree.
+func MergeSearchTrees(trees []*SearchTree) SearchTree {
+	if len(trees) == 0 {
+		panic("Expected at least 1 search tree to merge")
+	}
+
+	rootNode := NewSearchNode(nil, trees[0].rootNode.move)
+	rootNode.v = 0
+	childNodes := make(map[Move]*SearchNode)
+	lastChildNode := (*SearchNode)(nil)
+	for childNode := trees[0].rootNode.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		mergedChildNode := NewSearchNode(&rootNode, childNode.move)
+		mergedChildNode.p = 0
+		childNodes[childNode.move] = &mergedChildNode
+		// Keep the children in the same order as in the first tree
+		if lastChildNode == nil {
+			rootNode.firstChild = &mergedChildNode
+		} else {
+			lastChildNode.nextSibling = &mergedChildNode
+		}
+		lastChildNode = &mergedChildNode
+	}
+
+	for _, tree := range trees {
+		rootNode.n += tree.rootNode.n
+		rootNode.w += tree.rootNode.w
+		rootNode.v += tree.rootNode.v / float32(len(trees))
+		for childNode := tree.rootNode.firstChild; childNode != nil; childNode = childNode.nextSibling {
+			mergedChildNode, ok := childNodes[childNode.move]
+			if !ok {
+				panic("Expected the search trees to be for the same game")
+			}
+			mergedChildNode.n += childNode.n
+			mergedChildNode.w += childNode.w
+			mergedChildNode.p += childNode.p / float32(len(trees))
+			if childNode.isTerminal {
+				mergedChildNode.isTerminal = true
+				mergedChildNode.v = 1
+			}
+		}
+	}
+	if rootNode.n > 0 {
+		rootNode.q = rootNode.w / float32(rootNode.n)
+	}
+	for childNode := rootNode.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		if childNode.n > 0 {
+			childNode.q = childNode.w / float32(childNode.n)
+		}
+	}
+
+	return SearchTree{
+		game:     trees[0].game,
+		position: CopyPosition(&trees[0].position),
+		rootNode: &rootNode,
+	}
+}
+
+// DoRootParallelSearch searches a game on numTrees independent search trees, each on its own goroutine,
+// and merges the trees with MergeSearchTrees. Each tree has Dirichlet noise with a different seed,
+// so that the trees explore differently. The visits are split between the trees.
+// The evaluator must be safe to call from several goroutines at once.
+func DoRootParallelSearch(evaluatePosition Evaluator, game Game, numVisits int, numTrees int) SearchTree {
+	if numTrees < 1 {
+		panic("Expected at least 1 search tree")
+	}
+
+	trees := make([]*SearchTree, numTrees)
+	seed := uint64(rand.Int63())
+	var waitGroup sync.WaitGroup
+	for i := 0; i < numTrees; i++ {
+		// Split the visits as evenly as possible
+		numTreeVisits := numVisits / numTrees
+		if i < numVisits%numTrees {
+			numTreeVisits++
+		}
+
+		waitGroup.Add(1)
+		go func(i int, numTreeVisits int) {
+			defer waitGroup.Done()
+			tree := NewSearchTree(evaluatePosition, game)
+			ApplyDirichletNoiseWithSeed(&tree, seed+uint64(i))
+			for j := 0; j < numTreeVisits; j++ {
+				DoVisit(&tree, evaluatePosition)
+			}
+			trees[i] = &tree
+		}(i, numTreeVisits)
+	}
+	waitGroup.Wait()
+	return MergeSearchTrees(trees)
+}
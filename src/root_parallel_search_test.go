@@ -0,0 +1,72 @@
+package hexit
+
+import "testing"
+
+func TestMergeSearchTrees(t *testing.T) {
+	game := NewGame()
+	err, game := PlayGameMove(game, 2, 2)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	firstTree := NewSearchTree(EvaluatePositionRandomly, game)
+	secondTree := NewSearchTree(EvaluatePositionRandomly, game)
+	for i := 0; i < 100; i++ {
+		DoVisit(&firstTree, EvaluatePositionRandomly)
+	}
+	for i := 0; i < 300; i++ {
+		DoVisit(&secondTree, EvaluatePositionRandomly)
+	}
+
+	mergedTree := MergeSearchTrees([]*SearchTree{&firstTree, &secondTree})
+	if mergedTree.rootNode.n != 400 {
+		t.Errorf("Expected 400 visits, got %d", mergedTree.rootNode.n)
+	}
+	totalPolicy := float32(0)
+	for _, stats := range GetRootMoveStats(&mergedTree) {
+		firstStats := getMoveStats(findChildNode(t, firstTree.rootNode, stats.Move), firstTree.rootNode.n)
+		secondStats := getMoveStats(findChildNode(t, secondTree.rootNode, stats.Move), secondTree.rootNode.n)
+		if stats.N != firstStats.N+secondStats.N {
+			t.Errorf("Expected %s to have %d visits, got %d", FormatLocation(stats.Move), firstStats.N+secondStats.N, stats.N)
+		}
+		totalPolicy += stats.P
+	}
+	if totalPolicy < 0.999 || totalPolicy > 1.001 {
+		t.Errorf("Expected the merged policy to add up to 1, got %f", totalPolicy)
+	}
+	// The merged tree can still decide whether to swap
+	ShouldSwitchSides(&mergedTree)
+}
+
+func findChildNode(t *testing.T, node *SearchNode, move Move) *SearchNode {
+	for childNode := node.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		if childNode.move == move {
+			return childNode
+		}
+	}
+	t.Fatalf("Expected a child node for %s", FormatLocation(move))
+	return nil
+}
+
+func TestDoRootParallelSearch(t *testing.T) {
+	game := NewGame()
+	// Player 1 can win by playing at (4, 0)
+	game.Board = [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+	}
+
+	tree := DoRootParallelSearch(EvaluatePositionUniformly, game, 1001, 4)
+	if tree.rootNode.n != 1001 {
+		t.Errorf("Expected 1001 visits, got %d", tree.rootNode.n)
+	}
+	bestMove := GetBestMove(&tree)
+	if bestMove.Row != 4 || bestMove.Col != 0 {
+		t.Error("Failed to find the winning move")
+	}
+	if GetExpectedValueOfGame(&tree) < 0.5 {
+		t.Errorf("Expected Player 1 to be winning, got %f", GetExpectedValueOfGame(&tree))
+	}
+}
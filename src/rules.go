@@ -0,0 +1,52 @@
+package hexit
+
+import "fmt"
+
+// SwapRule is the variant of the swap rule (the "pie rule") that a game is played with.
+// Under the swap rule, Player 2 decides on move 2 whether to take over Player 1's first stone.
+type SwapRule byte
+
+const (
+	// SwapSidesRule: the first stone stays, and the players switch sides (see SwitchSides)
+	SwapSidesRule SwapRule = iota
+	// SwapPiecesRule: the first stone is mirrored into Player 2's color, and Player 1 moves next (see SwapPieces).
+	// It can only be used on square boards.
+	SwapPiecesRule
+	// NoSwapRule: there's no swap, and Player 2 places a stone on move 2
+	NoSwapRule
+)
+
+var swapRuleNames = []string{"swap-sides", "swap-pieces", "none"}
+
+// FormatSwapRule gets the name of a swap rule, such as "swap-pieces"
+func FormatSwapRule(swapRule SwapRule) string {
+	if int(swapRule) >= len(swapRuleNames) {
+		panic("Invalid swap rule")
+	}
+	return swapRuleNames[swapRule]
+}
+
+// ParseSwapRule parses the name of a swap rule: "swap-sides", "swap-pieces" or "none"
+func ParseSwapRule(text string) (error, SwapRule) {
+	for swapRule, name := range swapRuleNames {
+		if text == name {
+			return nil, SwapRule(swapRule)
+		}
+	}
+	return fmt.Errorf("Invalid swap rule %q", text), 0
+}
+
+// CanSwap checks whether it's move 2 and Player 2 can decide whether to swap
+func CanSwap(game Game) bool {
+	return game.MoveNum == 2 && game.SwapRule != NoSwapRule
+}
+
+// canSwapAfterMove checks whether Player 2 will be able to swap after the current move
+func canSwapAfterMove(game Game) bool {
+	return game.MoveNum == 1 && game.SwapRule != NoSwapRule
+}
+
+// mirrorLocation reflects a location across the board's long diagonal, which exchanges the players' edges
+func mirrorLocation(location BoardLocation) BoardLocation {
+	return BoardLocation{Row: location.Col, Col: location.Row}
+}
@@ -0,0 +1,26 @@
+package hexit
+
+import "testing"
+
+func TestParseSwapRule(t *testing.T) {
+	for _, swapRule := range []SwapRule{SwapSidesRule, SwapPiecesRule, NoSwapRule} {
+		err, parsedSwapRule := ParseSwapRule(FormatSwapRule(swapRule))
+		if err != nil || parsedSwapRule != swapRule {
+			t.Errorf("Expected %q to parse back into the same swap rule", FormatSwapRule(swapRule))
+		}
+	}
+	err, _ := ParseSwapRule("swap")
+	if err == nil {
+		t.Error("Expected an invalid swap rule")
+	}
+}
+
+func TestSwapRuleChangesHash(t *testing.T) {
+	if NewGame().Hash != NewGameWithRules(DefaultBoardSize, DefaultBoardSize, SwapSidesRule).Hash {
+		t.Error("Games should use the swap-sides rule by default")
+	}
+	if NewGame().Hash == NewGameWithRules(DefaultBoardSize, DefaultBoardSize, SwapPiecesRule).Hash ||
+		NewGame().Hash == NewGameWithRules(DefaultBoardSize, DefaultBoardSize, NoSwapRule).Hash {
+		t.Error("Games with different swap rules should have different hashes")
+	}
+}
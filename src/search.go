@@ -1,10 +1,12 @@
 package hexit
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 
 	tf "github.com/tensorflow/tensorflow/tensorflow/go"
+	exprand "golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/stat/distuv"
 )
 
@@ -29,51 +31,127 @@ type SearchNode struct {
 	parent      *SearchNode
 	firstChild  *SearchNode
 	nextSibling *SearchNode
+	// What this node shares with other nodes that have the same position, if the tree has a transposition table.
+	// It's nil until the node is first visited.
+	transposition *transpositionEntry
 }
 
 // SearchTree is an MCTS search tree
 type SearchTree struct {
 	game     Game
+	position Position
 	rootNode *SearchNode
+	// Transposition table, or nil. See EnableTranspositions.
+	transpositions *transpositionTable
 }
 
 // NewSearchNode creates a new SearchNode
 func NewSearchNode(parent *SearchNode, move Move) SearchNode {
 	nan := float32(math.NaN())
 	return SearchNode{
-		move:        move,
-		n:           0,
-		v:           nan,
-		q:           0,
-		w:           0,
-		p:           nan,
-		isTerminal:  false,
-		parent:      parent,
-		firstChild:  nil,
-		nextSibling: nil,
+		move:          move,
+		n:             0,
+		v:             nan,
+		q:             0,
+		w:             0,
+		p:             nan,
+		isTerminal:    false,
+		parent:        parent,
+		firstChild:    nil,
+		nextSibling:   nil,
+		transposition: nil,
 	}
 }
 
 // NewSearchTree creates a new SearchTree
 func NewSearchTree(evaluatePosition Evaluator, game Game) SearchTree {
-	if GetWinner(game.Board) != 0 {
+	position := getGamePosition(game)
+	if GetPositionWinner(&position) != 0 {
 		panic("Can't search from a terminal node")
 	}
 
 	rootNode := NewSearchNode(nil, Move{Row: 1000, Col: 1000})
 	searchTree := SearchTree{
 		game:     game,
+		position: position,
 		rootNode: &rootNode,
 	}
 	EvaluateAtNode(evaluatePosition, searchTree.rootNode, game)
 	return searchTree
 }
 
+// AdvanceSearchTree moves the root of a search tree to the position after a move, keeping what was searched below it.
+// Regular moves keep the subtree of the move's child node. Switching sides and not switching sides keep the whole tree,
+// since the stones and the color to move stay the same. Swapping pieces changes the board, so it starts a new tree.
+// Dirichlet noise can be applied again at the new root with ApplyDirichletNoise.
+// If the move ends the game, the tree can't be searched anymore.
+func AdvanceSearchTree(tree *SearchTree, evaluatePosition Evaluator, move GameMove) error {
+	err, game := ApplyGameMove(tree.game, move)
+	if err != nil {
+		return err
+	}
+
+	switch move.Type {
+	case SwitchSidesMove, DoNotSwitchSidesMove:
+		tree.game = game
+		if tree.transpositions != nil {
+			relinkTranspositions(tree, tree.rootNode, game)
+		}
+		return nil
+	case SwapPiecesMove:
+		transpositions := tree.transpositions
+		*tree = NewSearchTree(evaluatePosition, game)
+		if transpositions != nil {
+			tree.transpositions = transpositions
+			linkRootTransposition(tree)
+		}
+		return nil
+	}
+
+	newRootNode := (*SearchNode)(nil)
+	for childNode := tree.rootNode.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		if childNode.move == move.Location {
+			newRootNode = childNode
+		}
+	}
+	if newRootNode == nil {
+		panic("Expected a child node for every legal move")
+	}
+	// Detach the new root from the rest of the tree, so that the rest can be garbage collected
+	newRootNode.parent = nil
+	newRootNode.nextSibling = nil
+
+	tree.game = game
+	tree.position = getGamePosition(game)
+	tree.rootNode = newRootNode
+	if GetPositionWinner(&tree.position) != 0 {
+		tree.rootNode.isTerminal = true
+	} else if tree.rootNode.firstChild == nil {
+		// The move's node was never visited, so it hasn't been evaluated yet
+		EvaluateAtNode(evaluatePosition, tree.rootNode, game)
+	}
+	if tree.transpositions != nil {
+		linkRootTransposition(tree)
+	}
+	return nil
+}
+
 // ApplyDirichletNoise applies noise to the root node's policy estimates
 func ApplyDirichletNoise(newSearchTree *SearchTree) {
+	applyDirichletNoiseFromSource(newSearchTree, nil)
+}
+
+// ApplyDirichletNoiseWithSeed is like ApplyDirichletNoise, but draws the noise from its own random source with the given seed.
+// Unlike ApplyDirichletNoise, it doesn't share a random source with other goroutines.
+func ApplyDirichletNoiseWithSeed(newSearchTree *SearchTree, seed uint64) {
+	applyDirichletNoiseFromSource(newSearchTree, exprand.NewSource(seed))
+}
+
+// applyDirichletNoiseFromSource applies noise to the root node's policy estimates, using the global random source if source is nil
+func applyDirichletNoiseFromSource(newSearchTree *SearchTree, source exprand.Source) {
 	epsilon := float32(0.25)
 	alpha := 0.3
-	gammaDistribution := distuv.Gamma{Alpha: alpha, Beta: 1.0}
+	gammaDistribution := distuv.Gamma{Alpha: alpha, Beta: 1.0, Src: source}
 
 	totalNoise := float32(0)
 	noiseVector := make([]float32, 0)
@@ -93,14 +171,26 @@ func ApplyDirichletNoise(newSearchTree *SearchTree) {
 	}
 }
 
-type Evaluator = func(Board, byte) (float32, [5][5]float32)
+// Evaluator returns a value estimate and a policy estimate for each board square.
+// The policy estimates have the same dimensions as the board.
+// The evaluators here are safe to call from several goroutines at once, as DoParallelVisits does.
+type Evaluator = func(Board, byte) (float32, [][]float32)
+
+// newPolicyEstimates allocates policy estimates with the same dimensions as the board
+func newPolicyEstimates(board Board) [][]float32 {
+	policyEstimates := make([][]float32, len(board))
+	for i := range policyEstimates {
+		policyEstimates[i] = make([]float32, len(board[i]))
+	}
+	return policyEstimates
+}
 
 // EvaluatePositionRandomly returns random value and policy estimates for a position.
-func EvaluatePositionRandomly(board Board, player byte) (float32, [5][5]float32) {
+func EvaluatePositionRandomly(board Board, player byte) (float32, [][]float32) {
 	valueEstimate := rand.Float32()*2 - 1
-	policyEstimates := [5][5]float32{}
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
+	policyEstimates := newPolicyEstimates(board)
+	for i := range policyEstimates {
+		for j := range policyEstimates[i] {
 			policyEstimates[i][j] = rand.Float32()
 		}
 	}
@@ -108,62 +198,109 @@ func EvaluatePositionRandomly(board Board, player byte) (float32, [5][5]float32)
 }
 
 // EvaluatePositionUniformly returns the same value and policy estimates for every position
-func EvaluatePositionUniformly(board Board, player byte) (float32, [5][5]float32) {
+func EvaluatePositionUniformly(board Board, player byte) (float32, [][]float32) {
 	valueEstimate := float32(0)
-	policyEstimates := [5][5]float32{}
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
-			policyEstimates[i][j] = 1.0 / (5 * 5)
+	numRows, numCols := GetBoardDimensions(board)
+	policyEstimates := newPolicyEstimates(board)
+	for i := range policyEstimates {
+		for j := range policyEstimates[i] {
+			policyEstimates[i][j] = 1.0 / float32(numRows*numCols)
 		}
 	}
 	return valueEstimate, policyEstimates
 }
 
+// GetEvaluator gets an evaluator by name: "random", "uniform" or "nn".
+// The "nn" evaluator loads the model with InitializeModel.
+func GetEvaluator(name string) (error, Evaluator) {
+	switch name {
+	case "random":
+		return nil, EvaluatePositionRandomly
+	case "uniform":
+		return nil, EvaluatePositionUniformly
+	case "nn":
+		InitializeModel()
+		return nil, EvaluatePositionWithNN
+	}
+	return fmt.Errorf("Unknown evaluator %q", name), nil
+}
+
 var model *tf.SavedModel
 
+// modelOperations are the model's input and outputs, which are looked up once when the model is loaded
+type modelOperations struct {
+	boardInput   tf.Output
+	policyOutput tf.Output
+	valueOutput  tf.Output
+}
+
+var operations modelOperations
+
+// DefaultModelPath is where train.py saves the model
+const DefaultModelPath = "hexit_saved_model"
+
 func InitializeModel() {
+	InitializeModelFromPath(DefaultModelPath)
+}
+
+// InitializeModelFromPath loads the model for EvaluatePositionWithNN from a saved model folder, if it isn't loaded yet
+func InitializeModelFromPath(path string) {
 	if model == nil {
-		savedModel, err := tf.LoadSavedModel("hexit_saved_model", []string{"serve"}, nil)
+		savedModel, err := tf.LoadSavedModel(path, []string{"serve"}, nil)
 		if err != nil {
 			panic(err)
 		}
+
+		boardInputOperation := savedModel.Graph.Operation("boardInput")
+		policyOutputOperation := savedModel.Graph.Operation("policyOutput/Softmax")
+		valueOutputOperation := savedModel.Graph.Operation("valueOutput/Tanh")
+		if boardInputOperation == nil {
+			panic("boardInput operation not found")
+		}
+		if policyOutputOperation == nil {
+			panic("policyOutput operation not found")
+		}
+		if valueOutputOperation == nil {
+			panic("valueOutput operation not found")
+		}
+		operations = modelOperations{
+			boardInput:   boardInputOperation.Output(0),
+			policyOutput: policyOutputOperation.Output(0),
+			valueOutput:  valueOutputOperation.Output(0),
+		}
 		model = savedModel
 	}
 }
 
-func EvaluatePositionWithNN(board Board, player byte) (float32, [5][5]float32) {
+func EvaluatePositionWithNN(board Board, player byte) (float32, [][]float32) {
+	valueEstimates, policyEstimates := EvaluatePositionsWithNN([]Board{board}, []byte{player})
+	return valueEstimates[0], policyEstimates[0]
+}
+
+// EvaluatePositionsWithNN evaluates several positions at once, in a single batch.
+// The boards must all have the board size that the model was trained for.
+func EvaluatePositionsWithNN(boards []Board, players []byte) ([]float32, [][][]float32) {
 	if model == nil {
 		panic("Model not initialized")
 	}
 
-	squaresOccupiedByMyself, squaresOccupiedByOtherPlayer := GetOccupiedSquaresForNN(board, player)
-	boardInput := [][]float32{
-		append(squaresOccupiedByMyself, squaresOccupiedByOtherPlayer...),
+	boardInput := make([][]float32, len(boards))
+	for i, board := range boards {
+		squaresOccupiedByMyself, squaresOccupiedByOtherPlayer := GetOccupiedSquaresForNN(board, players[i])
+		boardInput[i] = append(squaresOccupiedByMyself, squaresOccupiedByOtherPlayer...)
 	}
 	boardInputTensor, err := tf.NewTensor(boardInput)
 	if err != nil {
 		panic(err)
 	}
 
-	boardInputOperation := model.Graph.Operation("boardInput")
-	policyOutputOperation := model.Graph.Operation("policyOutput/Softmax")
-	valueOutputOperation := model.Graph.Operation("valueOutput/Tanh")
-	if boardInputOperation == nil {
-		panic("boardInput operation not found")
-	}
-	if policyOutputOperation == nil {
-		panic("policyOutput operation not found")
-	}
-	if valueOutputOperation == nil {
-		panic("valueOutput operation not found")
-	}
 	result, err := model.Session.Run(
 		map[tf.Output]*tf.Tensor{
-			boardInputOperation.Output(0): boardInputTensor,
+			operations.boardInput: boardInputTensor,
 		},
 		[]tf.Output{
-			policyOutputOperation.Output(0),
-			valueOutputOperation.Output(0),
+			operations.policyOutput,
+			operations.valueOutput,
 		},
 		nil,
 	)
@@ -174,20 +311,36 @@ func EvaluatePositionWithNN(board Board, player byte) (float32, [5][5]float32) {
 	policyOutputs := result[0].Value().([][]float32)
 	valueOutputs := result[1].Value().([][]float32)
 
+	valueEstimates := make([]float32, len(boards))
+	policyEstimates := make([][][]float32, len(boards))
+	for i, board := range boards {
+		valueEstimates[i], policyEstimates[i] = convertNNOutputs(board, players[i], valueOutputs[i][0], policyOutputs[i])
+	}
+	return valueEstimates, policyEstimates
+}
+
+// convertNNOutputs converts the model's outputs for one position, which are for the flipped board, back to the board's point of view
+func convertNNOutputs(board Board, player byte, valueOutput float32, policyOutput []float32) (float32, [][]float32) {
+	numRows, numCols := GetBoardDimensions(board)
+	if uint(len(policyOutput)) != numRows*numCols {
+		panic("Model was trained for a different board size")
+	}
+
 	valueEstimate := float32(0.0)
 	if player == 1 {
-		valueEstimate = valueOutputs[0][0]
+		valueEstimate = valueOutput
 	} else {
-		valueEstimate = -valueOutputs[0][0]
+		valueEstimate = -valueOutput
 	}
 
-	policyEstimates := [5][5]float32{}
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
+	// The policy is for the flipped board, which has its rows and columns swapped for Player 2
+	policyEstimates := newPolicyEstimates(board)
+	for i := uint(0); i < numRows; i++ {
+		for j := uint(0); j < numCols; j++ {
 			if player == 1 {
-				policyEstimates[i][j] = policyOutputs[0][i*5+j]
+				policyEstimates[i][j] = policyOutput[i*numCols+j]
 			} else {
-				policyEstimates[j][i] = policyOutputs[0][i*5+j]
+				policyEstimates[i][j] = policyOutput[j*numRows+i]
 			}
 		}
 	}
@@ -202,18 +355,24 @@ func EvaluateAtNode(evaluatePosition Evaluator, node *SearchNode, game Game) {
 	}
 
 	valueEstimate, policyEstimates := evaluatePosition(game.Board, game.CurrentPlayer)
+	expandNode(node, game.Board, valueEstimate, policyEstimates)
+}
+
+// expandNode stores an evaluation of a node's position, and adds a child node for each legal move
+func expandNode(node *SearchNode, board Board, valueEstimate float32, policyEstimates [][]float32) {
 	node.v = valueEstimate
 
 	firstChildNode := (*SearchNode)(nil)
 	totalLegalPolicy := float32(0.0)
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
-			if game.Board[i][j] != 0 {
+	numRows, numCols := GetBoardDimensions(board)
+	for i := uint(0); i < numRows; i++ {
+		for j := uint(0); j < numCols; j++ {
+			if board[i][j] != 0 {
 				// Illegal move
 				continue
 			}
 			totalLegalPolicy += policyEstimates[i][j]
-			childNode := NewSearchNode(node, Move{Row: uint(i), Col: uint(j)})
+			childNode := NewSearchNode(node, Move{Row: i, Col: j})
 			childNode.p = policyEstimates[i][j]
 			childNode.nextSibling = firstChildNode
 			firstChildNode = &childNode
@@ -245,8 +404,12 @@ func CalculateUctValue(node *SearchNode, numParentVisits uint) float32 {
 }
 
 // CalculateFirstMoveUctValue is like CalculateUctValue, but for the very first move.
-// The first move should be as close to equal as possible, so instead of Q+U, use U-abs(Q).
-func CalculateFirstMoveUctValue(node *SearchNode, numParentVisits uint) float32 {
+// If Player 2 can swap, the first move should be as close to equal as possible, so instead of Q+U, use U-abs(Q).
+// This is the same for both swap rules, since swapping pieces gives the same position as switching sides, mirrored.
+func CalculateFirstMoveUctValue(node *SearchNode, numParentVisits uint, swapRule SwapRule) float32 {
+	if swapRule == NoSwapRule {
+		return CalculateUctValue(node, numParentVisits)
+	}
 	if node.isTerminal {
 		// If the move wins the game, Player 2 can't switch sides.
 		// To make sure Player 1 plays the winning move, use the usual UCT value (Q+U)
@@ -255,23 +418,25 @@ func CalculateFirstMoveUctValue(node *SearchNode, numParentVisits uint) float32
 	return calculateUctU(node, numParentVisits) - float32(math.Abs(float64(node.q)))
 }
 
-// DoVisit performs one iteration of tree search.
-func DoVisit(tree *SearchTree, evaluatePosition Evaluator) {
-	// Select a leaf node to visit
+// selectLeaf descends from the root to a leaf node, choosing the child with the highest UCT value at each step.
+// It returns the leaf node, along with its game and position. The game has no Board.
+func selectLeaf(tree *SearchTree) (*SearchNode, Game, Position) {
 	currentNode := tree.rootNode
 	currentGame := tree.game
+	currentPosition := CopyPosition(&tree.position)
 	var err error
 	for currentNode.firstChild != nil {
 		// While we're not at a leaf node:
 		bestCandidateNode := (*SearchNode)(nil)
 		bestUctValue := float32(math.Inf(-1))
+		numParentVisits := uint(getSelectionNode(tree, currentNode).n)
 		candidateNode := currentNode.firstChild
 		for candidateNode != nil {
 			var uctValue float32
 			if currentGame.MoveNum == 1 {
-				uctValue = CalculateFirstMoveUctValue(candidateNode, uint(currentNode.n))
+				uctValue = CalculateFirstMoveUctValue(getSelectionNode(tree, candidateNode), numParentVisits, currentGame.SwapRule)
 			} else {
-				uctValue = CalculateUctValue(candidateNode, uint(currentNode.n))
+				uctValue = CalculateUctValue(getSelectionNode(tree, candidateNode), numParentVisits)
 			}
 			if math.IsNaN(float64(uctValue)) {
 				panic("UCT value should not be NaN")
@@ -283,35 +448,37 @@ func DoVisit(tree *SearchTree, evaluatePosition Evaluator) {
 			candidateNode = candidateNode.nextSibling
 		}
 		currentNode = bestCandidateNode
-		// Skip the side-switching move
-		if currentGame.MoveNum == 2 {
+		// Skip the swap decision
+		if CanSwap(currentGame) {
 			err, currentGame = DoNotSwitchSides(currentGame)
 			if err != nil {
 				panic(err)
 			}
 		}
-		err, currentGame = PlayGameMove(currentGame, bestCandidateNode.move.Row, bestCandidateNode.move.Col)
+		err, currentGame = playGameMoveOnPosition(currentGame, &currentPosition, bestCandidateNode.move.Row, bestCandidateNode.move.Col)
 		if err != nil {
 			panic(err)
 		}
+		if tree.transpositions != nil && currentNode.transposition == nil {
+			currentNode.transposition = getTransposition(tree.transpositions, currentGame.Hash)
+		}
 	}
 
-	// Expand the selected leaf node
-	winner := GetWinner(currentGame.Board)
-	if winner != 0 {
-		currentNode.isTerminal = true
-		currentNode.v = 1
-	} else {
-		EvaluateAtNode(evaluatePosition, currentNode, currentGame)
-	}
+	return currentNode, currentGame, currentPosition
+}
 
-	// Back up the evaluated value
-	visitValue := currentNode.v
-	nodeToUpdate := currentNode
+// backUpValue adds a visit with the given value to a node, and to each of its ancestors from the other player's point of view.
+// If the tree shares statistics between transpositions, the positions along the path are updated too.
+func backUpValue(tree *SearchTree, node *SearchNode, visitValue float32) {
+	nodeToUpdate := node
 	for nodeToUpdate != nil {
 		nodeToUpdate.w += visitValue
 		nodeToUpdate.n++
 		nodeToUpdate.q = nodeToUpdate.w / float32(nodeToUpdate.n)
+		if isSharingStats(tree) && nodeToUpdate.transposition != nil {
+			nodeToUpdate.transposition.w += visitValue
+			nodeToUpdate.transposition.n++
+		}
 
 		if nodeToUpdate.parent == nil {
 			break
@@ -322,6 +489,29 @@ func DoVisit(tree *SearchTree, evaluatePosition Evaluator) {
 	}
 }
 
+// DoVisit performs one iteration of tree search.
+func DoVisit(tree *SearchTree, evaluatePosition Evaluator) {
+	// Select a leaf node to visit
+	leafNode, leafGame, leafPosition := selectLeaf(tree)
+
+	// Expand the selected leaf node
+	winner := GetPositionWinner(&leafPosition)
+	if winner != 0 {
+		leafNode.isTerminal = true
+		leafNode.v = 1
+	} else {
+		leafBoard := GetPositionBoard(&leafPosition)
+		if !expandFromTransposition(leafNode, leafBoard) {
+			valueEstimate, policyEstimates := evaluatePosition(leafBoard, leafGame.CurrentPlayer)
+			storeTranspositionEvaluation(leafNode, valueEstimate, policyEstimates)
+			expandNode(leafNode, leafBoard, valueEstimate, policyEstimates)
+		}
+	}
+
+	// Back up the evaluated value
+	backUpValue(tree, leafNode, leafNode.v)
+}
+
 // GetBestMove gets the estimated best move at the root of a search tree
 func GetBestMove(tree *SearchTree) Move {
 	maxVisits := -1
@@ -357,8 +547,8 @@ func GetMoveWithTemperatureOne(tree *SearchTree) Move {
 // GetExpectedValueOfGame gets the expected value of the game.
 // It's +1 if Player 1 will win, and -1 if Player 2 will win.
 func GetExpectedValueOfGame(tree *SearchTree) float32 {
-	if tree.game.MoveNum == 1 {
-		// Player 2 can switch sides
+	if canSwapAfterMove(tree.game) {
+		// Player 2 can swap
 		totalVisits := 0
 		totalAdjustedQ := float32(0)
 		for childNode := tree.rootNode.firstChild; childNode != nil; childNode = childNode.nextSibling {
@@ -370,9 +560,9 @@ func GetExpectedValueOfGame(tree *SearchTree) float32 {
 			totalAdjustedQ += float32(childNode.n) * q
 		}
 		return totalAdjustedQ / float32(totalVisits)
-	} else if tree.game.MoveNum == 2 {
+	} else if CanSwap(tree.game) {
 		return float32(
-			// We can switch sides if the root node's Q is unfavorable
+			// We can swap if the root node's Q is unfavorable
 			-math.Abs(float64(tree.rootNode.q)),
 		)
 	} else if tree.game.CurrentPlayer == 1 {
@@ -384,8 +574,9 @@ func GetExpectedValueOfGame(tree *SearchTree) float32 {
 	}
 }
 
+// ShouldSwitchSides decides whether Player 2 should swap on move 2, with either swap rule
 func ShouldSwitchSides(tree *SearchTree) bool {
-	if tree.game.MoveNum != 2 {
+	if !CanSwap(tree.game) {
 		panic("Can only switch sides on move 2")
 	}
 	return tree.rootNode.q > 0
@@ -30,15 +30,29 @@ func TestNumLegalMoves(t *testing.T) {
 	}
 }
 
+func TestNumLegalMovesOnLargerBoard(t *testing.T) {
+	game := NewGameWithBoardSize(9)
+	tree := NewSearchTree(EvaluatePositionUniformly, game)
+
+	numLegalMoves := 0
+	for childNode := tree.rootNode.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		numLegalMoves++
+	}
+
+	if numLegalMoves != 81 {
+		t.Errorf("Expected 81 legal moves on a 9x9 board, but got %d", numLegalMoves)
+	}
+}
+
 func TestDoVisit(t *testing.T) {
 	game := NewGame()
 	// Player 1 can win by playing at (4, 0)
-	game.Board = [5][5]byte{
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
+	game.Board = [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
 	}
 
 	tree := NewSearchTree(EvaluatePositionUniformly, game)
@@ -63,12 +77,12 @@ func TestDoVisit(t *testing.T) {
 func TestGetBestMovePlayerOne(t *testing.T) {
 	game := NewGame()
 	// Player 1 can win by playing at (4, 0)
-	game.Board = [5][5]byte{
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
+	game.Board = [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
 	}
 
 	tree := NewSearchTree(EvaluatePositionUniformly, game)
@@ -86,12 +100,12 @@ func TestGetBestMovePlayerTwo(t *testing.T) {
 	game := NewGame()
 	// Player 2 can win by playing at (0, 4)
 	game.CurrentPlayer = 2
-	game.Board = [5][5]byte{
-		[5]byte{2, 2, 2, 2, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
+	game.Board = [][]byte{
+		[]byte{2, 2, 2, 2, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
 	}
 
 	tree := NewSearchTree(EvaluatePositionUniformly, game)
@@ -111,12 +125,12 @@ func newGameWithSideSwitching() Game {
 	game.MoveNum = 1
 	game.CurrentPlayer = 1
 	// If Player 1 plays anywhere other than (3, 0), Player 2 can switch sides and immediately win.
-	game.Board = [5][5]byte{
-		[5]byte{1, 1, 1, 1, 1},
-		[5]byte{1, 1, 1, 1, 1},
-		[5]byte{1, 1, 1, 1, 1},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{2, 0, 0, 0, 0},
+	game.Board = [][]byte{
+		[]byte{1, 1, 1, 1, 1},
+		[]byte{1, 1, 1, 1, 1},
+		[]byte{1, 1, 1, 1, 1},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{2, 0, 0, 0, 0},
 	}
 	return game
 }
@@ -135,6 +149,50 @@ func TestEvalWithSideSwitching(t *testing.T) {
 	}
 }
 
+func TestEvalWithSwappedPieces(t *testing.T) {
+	game := newGameWithSideSwitching()
+	game.SwapRule = SwapPiecesRule
+	game.Hash = ComputeGameHash(game)
+	tree := NewSearchTree(EvaluatePositionUniformly, game)
+	for i := 0; i < 1000; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+
+	bestMove := GetBestMove(&tree)
+	if bestMove.Row != 3 || bestMove.Col != 0 {
+		PrintVisitDistribution(tree.rootNode)
+		t.Error("Swapping pieces should be as good for Player 2 as switching sides")
+	}
+}
+
+func TestCalculateFirstMoveUctValueWithoutSwap(t *testing.T) {
+	node := NewSearchNode(nil, Move{Row: 0, Col: 0})
+	node.p = 0.5
+	node.n = 1
+	node.q = 0.5
+	if CalculateFirstMoveUctValue(&node, 2, NoSwapRule) != CalculateUctValue(&node, 2) {
+		t.Error("Without the swap rule, the first move should be picked like any other move")
+	}
+	if CalculateFirstMoveUctValue(&node, 2, SwapSidesRule) >= CalculateUctValue(&node, 2) {
+		t.Error("With the swap rule, a strong first move should be less attractive")
+	}
+}
+
+func TestGetExpectedValueOfGameWithoutSwap(t *testing.T) {
+	game := newGameWithSideSwitching()
+	game.SwapRule = NoSwapRule
+	game.Hash = ComputeGameHash(game)
+	tree := NewSearchTree(EvaluatePositionUniformly, game)
+	for i := 0; i < 1000; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+
+	expectedValue := GetExpectedValueOfGame(&tree)
+	if expectedValue < 0.8 {
+		t.Error("Without the swap rule, Player 1 has a winning move!")
+	}
+}
+
 func TestGetExpectedValueOfGame(t *testing.T) {
 	game := NewGame()
 	tree := NewSearchTree(EvaluatePositionUniformly, game)
@@ -181,12 +239,12 @@ func TestGetExpectedValueOfGamePlayerOneHasWinningFirstMove(t *testing.T) {
 	game := NewGame()
 	// Player 1 can win by playing at (4, 0)
 	game.MoveNum = 1
-	game.Board = [5][5]byte{
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
+	game.Board = [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
 	}
 
 	tree := NewSearchTree(EvaluatePositionUniformly, game)
@@ -204,12 +262,12 @@ func TestGetExpectedValueOfGamePlayerOneHasWinningMove(t *testing.T) {
 	game := NewGame()
 	// Player 1 can win by playing at (4, 0)
 	game.MoveNum = 5
-	game.Board = [5][5]byte{
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{1, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
+	game.Board = [][]byte{
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{1, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
 	}
 
 	tree := NewSearchTree(EvaluatePositionUniformly, game)
@@ -228,12 +286,12 @@ func TestGetExpectedValueOfGamePlayerTwoHasWinningMove(t *testing.T) {
 	// Player 2 can win by playing at (0, 4)
 	game.MoveNum = 5
 	game.CurrentPlayer = 2
-	game.Board = [5][5]byte{
-		[5]byte{2, 2, 2, 2, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
-		[5]byte{0, 0, 0, 0, 0},
+	game.Board = [][]byte{
+		[]byte{2, 2, 2, 2, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
+		[]byte{0, 0, 0, 0, 0},
 	}
 
 	tree := NewSearchTree(EvaluatePositionUniformly, game)
@@ -246,3 +304,105 @@ func TestGetExpectedValueOfGamePlayerTwoHasWinningMove(t *testing.T) {
 		t.Error("Player 2 has a winning move!")
 	}
 }
+
+func TestAdvanceSearchTree(t *testing.T) {
+	game := NewGame()
+	tree := NewSearchTree(EvaluatePositionRandomly, game)
+	for i := 0; i < 500; i++ {
+		DoVisit(&tree, EvaluatePositionRandomly)
+	}
+
+	bestMove := GetBestMove(&tree)
+	numBestMoveVisits := findChildNode(t, tree.rootNode, bestMove).n
+	err := AdvanceSearchTree(&tree, EvaluatePositionRandomly, GameMove{Type: RegularMove, Location: bestMove})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if tree.rootNode.n != numBestMoveVisits || tree.rootNode.parent != nil {
+		t.Errorf("Expected the new root to keep its %d visits, got %d", numBestMoveVisits, tree.rootNode.n)
+	}
+	if !CanSwap(tree.game) || tree.game.Board[bestMove.Row][bestMove.Col] != 1 {
+		t.Error("Expected the tree's game to be at the swap decision, after the first stone")
+	}
+
+	// Not switching sides, like switching sides, keeps the whole tree
+	rootNode := tree.rootNode
+	err = AdvanceSearchTree(&tree, EvaluatePositionRandomly, GameMove{Type: DoNotSwitchSidesMove})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if tree.rootNode != rootNode || CanSwap(tree.game) {
+		t.Error("Expected not switching sides to keep the root")
+	}
+
+	// Moves that were never visited are evaluated when the tree is advanced to them
+	unvisitedMove := (*SearchNode)(nil)
+	for childNode := tree.rootNode.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		if childNode.n == 0 {
+			unvisitedMove = childNode
+		}
+	}
+	if unvisitedMove == nil {
+		t.Fatal("Expected a move with no visits")
+	}
+	err = AdvanceSearchTree(&tree, EvaluatePositionRandomly, GameMove{Type: RegularMove, Location: unvisitedMove.move})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if tree.rootNode.firstChild == nil {
+		t.Error("Expected the new root to be evaluated")
+	}
+	DoVisit(&tree, EvaluatePositionRandomly)
+
+	err = AdvanceSearchTree(&tree, EvaluatePositionRandomly, GameMove{Type: RegularMove, Location: bestMove})
+	if err == nil {
+		t.Error("Expected an error for an occupied location")
+	}
+}
+
+func TestAdvanceSearchTreeAcrossSwap(t *testing.T) {
+	for _, swapRule := range []SwapRule{SwapSidesRule, SwapPiecesRule} {
+		game := NewGameWithRules(5, 5, swapRule)
+		err, game := PlayGameMove(game, 0, 1)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		tree := NewSearchTree(EvaluatePositionRandomly, game)
+		for i := 0; i < 100; i++ {
+			DoVisit(&tree, EvaluatePositionRandomly)
+		}
+
+		err = AdvanceSearchTree(&tree, EvaluatePositionRandomly, GetSwapMove(game))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if swapRule == SwapSidesRule && (tree.rootNode.n != 100 || !tree.game.SwitchedSides) {
+			t.Error("Expected switching sides to keep the tree")
+		}
+		if swapRule == SwapPiecesRule && (tree.rootNode.n != 0 || tree.game.Board[1][0] != 2) {
+			t.Error("Expected swapping pieces to start a new tree from the mirrored position")
+		}
+		for i := 0; i < 100; i++ {
+			DoVisit(&tree, EvaluatePositionRandomly)
+		}
+	}
+}
+
+func TestAdvanceSearchTreeToEndOfGame(t *testing.T) {
+	err, board := ParsePosition("xo---/xo---/xo---/xo---/-----")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, game := NewGameFromBoard(board, SwapSidesRule)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	tree := NewSearchTree(EvaluatePositionUniformly, game)
+	err = AdvanceSearchTree(&tree, EvaluatePositionUniformly, GameMove{Type: RegularMove, Location: Move{Row: 4, Col: 0}})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !tree.rootNode.isTerminal || GetWinner(tree.game.Board) != 1 {
+		t.Error("Expected the game to be over")
+	}
+}
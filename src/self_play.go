@@ -10,18 +10,24 @@ import (
 )
 
 type moveSnapshotWithoutWinner struct {
+	// Player whose turn it was. Usually this alternates, but not after Player 2 swaps pieces.
+	player                       byte
 	normalizedVisitCounts        []float32
 	squaresOccupiedByMyself      []float32
 	squaresOccupiedByOtherPlayer []float32
 }
 
 type trainingGameBuilder struct {
+	numRows        uint
+	numCols        uint
 	moveSnapshots  []*moveSnapshotWithoutWinner
 	didSwitchSides bool
 }
 
-func newTrainingGameBuilder() trainingGameBuilder {
+func newTrainingGameBuilder(numRows uint, numCols uint) trainingGameBuilder {
 	return trainingGameBuilder{
+		numRows:        numRows,
+		numCols:        numCols,
 		moveSnapshots:  make([]*moveSnapshotWithoutWinner, 0),
 		didSwitchSides: false,
 	}
@@ -30,23 +36,25 @@ func newTrainingGameBuilder() trainingGameBuilder {
 func recordTrainingGameMove(builder *trainingGameBuilder, game Game, normalizedVisitCounts []float32) {
 	squaresOccupiedByMyself, squaresOccupiedByOtherPlayer := GetOccupiedSquaresForNN(game.Board, game.CurrentPlayer)
 	builder.moveSnapshots = append(builder.moveSnapshots, &moveSnapshotWithoutWinner{
+		player:                       game.CurrentPlayer,
 		normalizedVisitCounts:        normalizedVisitCounts,
 		squaresOccupiedByMyself:      squaresOccupiedByMyself,
 		squaresOccupiedByOtherPlayer: squaresOccupiedByOtherPlayer,
 	})
 }
 
+// recordTrainingGameSwitchedSides records that Player 2 switched sides, so Player 1's first move was played for the other player.
+// Swapping pieces doesn't need to be recorded, since the players keep their colors.
 func recordTrainingGameSwitchedSides(builder *trainingGameBuilder) {
 	builder.didSwitchSides = true
 }
 
 func buildTrainingGame(builder *trainingGameBuilder, winner byte) TrainingGame {
 	moveSnapshots := make([]*TrainingGame_MoveSnapshot, 0)
-	player := byte(1)
 
 	for i, moveSnapshotWithoutWinner := range builder.moveSnapshots {
 		var trainingGameWinner TrainingGame_Player
-		originalPlayer := player
+		originalPlayer := moveSnapshotWithoutWinner.player
 		if i == 0 && builder.didSwitchSides {
 			originalPlayer = OtherPlayer(originalPlayer)
 		}
@@ -62,48 +70,69 @@ func buildTrainingGame(builder *trainingGameBuilder, winner byte) TrainingGame {
 			SquaresOccupiedByMyself:      moveSnapshotWithoutWinner.squaresOccupiedByMyself,
 			SquaresOccupiedByOtherPlayer: moveSnapshotWithoutWinner.squaresOccupiedByOtherPlayer,
 		})
-
-		player = OtherPlayer(player)
 	}
 
-	return TrainingGame{MoveSnapshots: moveSnapshots}
+	return TrainingGame{
+		MoveSnapshots: moveSnapshots,
+		NumRows:       uint32(builder.numRows),
+		NumCols:       uint32(builder.numCols),
+	}
 }
 
 var numVisits = 800
 
-func playTrainingGame() TrainingGame {
+func playTrainingGame(numRows uint, numCols uint, swapRule SwapRule, numThreads int) TrainingGame {
 	rand.Seed(time.Now().UTC().UnixNano())
 
 	var err error
-	game := NewGame()
-	trainingGameBuilder := newTrainingGameBuilder()
+	game := NewGameWithRules(numRows, numCols, swapRule)
+	trainingGameBuilder := newTrainingGameBuilder(numRows, numCols)
 
-	for GetWinner(game.Board) == 0 {
-		tree := NewSearchTree(EvaluatePositionRandomly, game)
+	// The tree is kept from move to move, so that what was searched below the move that was played isn't lost
+	tree := NewSearchTree(EvaluatePositionRandomly, game)
+	for GetGameWinner(game) == 0 {
 		ApplyDirichletNoise(&tree)
-		for i := 0; i < numVisits; i++ {
-			DoVisit(&tree, EvaluatePositionRandomly)
-		}
+		DoParallelVisits(&tree, EvaluatePositionRandomly, numVisits, numThreads)
 
-		if game.MoveNum == 2 {
+		if CanSwap(game) {
+			swapMove := GameMove{Type: DoNotSwitchSidesMove}
 			if ShouldSwitchSides(&tree) {
-				err, game = SwitchSides(game)
-				recordTrainingGameSwitchedSides(&trainingGameBuilder)
-			} else {
-				err, game = DoNotSwitchSides(game)
+				swapMove = GetSwapMove(game)
 			}
+			err, game = ApplyGameMove(game, swapMove)
 			if err != nil {
 				panic(err)
 			}
+			err = AdvanceSearchTree(&tree, EvaluatePositionRandomly, swapMove)
+			if err != nil {
+				panic(err)
+			}
+			if swapMove.Type == SwapPiecesMove {
+				// Swapping pieces changes the board, so search again from the new position
+				continue
+			}
+			if swapMove.Type == SwitchSidesMove {
+				recordTrainingGameSwitchedSides(&trainingGameBuilder)
+			}
 		}
 
-		normalizedVisitCounts := make([]float32, 5*5)
+		// Visits from earlier moves are kept, so there can be more than numVisits of them
+		totalVisits := uint32(0)
+		for childNode := tree.rootNode.firstChild; childNode != nil; childNode = childNode.nextSibling {
+			totalVisits += childNode.n
+		}
+		normalizedVisitCounts := make([]float32, numRows*numCols)
 		for childNode := tree.rootNode.firstChild; childNode != nil; childNode = childNode.nextSibling {
 			row, col := childNode.move.Row, childNode.move.Col
 			if game.Board[row][col] != 0 {
 				panic("Illegal move")
 			}
-			normalizedVisitCounts[row*5+col] = float32(childNode.n) / float32(numVisits)
+			// Visit counts are ordered the same way as the flipped board squares (see training_game.proto)
+			boardSquareIndex := row*numCols + col
+			if game.CurrentPlayer == 2 {
+				boardSquareIndex = col*numRows + row
+			}
+			normalizedVisitCounts[boardSquareIndex] = float32(childNode.n) / float32(totalVisits)
 		}
 		recordTrainingGameMove(&trainingGameBuilder, game, normalizedVisitCounts)
 
@@ -112,14 +141,18 @@ func playTrainingGame() TrainingGame {
 		if err != nil {
 			panic(err)
 		}
+		err = AdvanceSearchTree(&tree, EvaluatePositionRandomly, GameMove{Type: RegularMove, Location: move})
+		if err != nil {
+			panic(err)
+		}
 	}
 
-	winner := GetWinner(game.Board)
+	winner := GetGameWinner(game)
 	return buildTrainingGame(&trainingGameBuilder, winner)
 }
 
-func GenerateTrainingGame(outputFilename string) {
-	trainingGame := playTrainingGame()
+func GenerateTrainingGame(outputFilename string, numRows uint, numCols uint, swapRule SwapRule, numThreads int) {
+	trainingGame := playTrainingGame(numRows, numCols, swapRule, numThreads)
 
 	trainingGameBytes, err := proto.Marshal(&trainingGame)
 	if err != nil {
@@ -3,27 +3,28 @@ package hexit
 import "testing"
 
 func TestNewTrainingGameBuilder(t *testing.T) {
-	builder := newTrainingGameBuilder()
+	builder := newTrainingGameBuilder(DefaultBoardSize, DefaultBoardSize)
 	if len(builder.moveSnapshots) != 0 {
 		t.Error("New builder should have no moves")
 	}
 }
 
 func makeUniformVisitCounts(board Board) []float32 {
+	numRows, numCols := GetBoardDimensions(board)
 	numLegalMoves := 0
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
+	for i := uint(0); i < numRows; i++ {
+		for j := uint(0); j < numCols; j++ {
 			if board[i][j] == 0 {
 				numLegalMoves++
 			}
 		}
 	}
 
-	normalizedVisitCounts := make([]float32, 5*5)
-	for i := 0; i < 5; i++ {
-		for j := 0; j < 5; j++ {
+	normalizedVisitCounts := make([]float32, numRows*numCols)
+	for i := uint(0); i < numRows; i++ {
+		for j := uint(0); j < numCols; j++ {
 			if board[i][j] == 0 {
-				normalizedVisitCounts[5*i+j] = 1 / float32(numLegalMoves)
+				normalizedVisitCounts[numCols*i+j] = 1 / float32(numLegalMoves)
 			}
 		}
 	}
@@ -33,7 +34,7 @@ func makeUniformVisitCounts(board Board) []float32 {
 
 func TestBuildSimpleTrainingGame(t *testing.T) {
 	game := NewGame()
-	builder := newTrainingGameBuilder()
+	builder := newTrainingGameBuilder(DefaultBoardSize, DefaultBoardSize)
 
 	recordTrainingGameMove(&builder, game, makeUniformVisitCounts(game.Board))
 	trainingGame := buildTrainingGame(&builder, 1)
@@ -43,9 +44,24 @@ func TestBuildSimpleTrainingGame(t *testing.T) {
 	}
 }
 
+func TestBuildTrainingGameRecordsBoardSize(t *testing.T) {
+	game := NewGameWithBoardSize(7)
+	builder := newTrainingGameBuilder(7, 7)
+
+	recordTrainingGameMove(&builder, game, makeUniformVisitCounts(game.Board))
+	trainingGame := buildTrainingGame(&builder, 1)
+
+	if trainingGame.NumRows != 7 || trainingGame.NumCols != 7 {
+		t.Errorf("Expected a 7x7 board but got %dx%d", trainingGame.NumRows, trainingGame.NumCols)
+	}
+	if len(trainingGame.MoveSnapshots[0].SquaresOccupiedByMyself) != 7*7 {
+		t.Error("Expected one input per board square")
+	}
+}
+
 func TestBuildTrainingGameWithSideSwitching(t *testing.T) {
 	game := NewGame()
-	builder := newTrainingGameBuilder()
+	builder := newTrainingGameBuilder(DefaultBoardSize, DefaultBoardSize)
 
 	recordTrainingGameMove(&builder, game, makeUniformVisitCounts(game.Board))
 	err, game := PlayGameMove(game, 0, 0)
@@ -70,3 +86,30 @@ func TestBuildTrainingGameWithSideSwitching(t *testing.T) {
 		t.Errorf("Expected Player 1 to win (playing as Player 2's color)")
 	}
 }
+
+func TestBuildTrainingGameWithSwappedPieces(t *testing.T) {
+	game := NewGameWithRules(DefaultBoardSize, DefaultBoardSize, SwapPiecesRule)
+	builder := newTrainingGameBuilder(DefaultBoardSize, DefaultBoardSize)
+
+	recordTrainingGameMove(&builder, game, makeUniformVisitCounts(game.Board))
+	err, game := PlayGameMove(game, 0, 1)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	err, game = SwapPieces(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	recordTrainingGameMove(&builder, game, makeUniformVisitCounts(game.Board))
+	// Player 1 keeps their color, and moves again after the swap
+	trainingGame := buildTrainingGame(&builder, 1)
+
+	if trainingGame.MoveSnapshots[0].Winner != TrainingGame_MYSELF {
+		t.Errorf("Expected Player 1 to win")
+	}
+	if trainingGame.MoveSnapshots[1].Winner != TrainingGame_MYSELF {
+		t.Errorf("Expected Player 1 to win after Player 2 swapped pieces")
+	}
+}
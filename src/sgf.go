@@ -0,0 +1,431 @@
+package hexit
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// Game records are stored in the SGF dialect used by HexGui and Little Golem, for example:
+//
+//   (;FF[4]GM[11]SZ[5]RU[swap-sides]PB[Alice]PW[Bob]RE[B+];B[c3];W[swap-sides];W[b4])
+//
+// Black is Player 1, who moves first and connects the top and bottom.
+// White is Player 2, who connects the left and right.
+// RU is hexit's name for the swap rule, as written by FormatSwapRule.
+// A game that didn't start from an empty board has its starting stones in AB (Black) and AW (White) on the root node.
+
+// sgfGameNumber is the SGF game type for Hex
+const sgfGameNumber = "11"
+
+// Little Golem swaps pieces, and writes it as "swap"
+const sgfLegacySwapToken = "swap"
+
+// sgfSetupKeys are the properties for the starting stones of each player: sgfSetupKeys[player-1]
+var sgfSetupKeys = []string{"AB", "AW"}
+
+// GameRecord is a game with the metadata that's saved along with it
+type GameRecord struct {
+	// Name of the player who moved first
+	PlayerOneName string
+	// Name of the player who moved second
+	PlayerTwoName string
+	// Player whose color won the game, or 0 if it's unknown or the game isn't finished
+	Winner byte
+	// Game with its full move history
+	Game Game
+	// Comment on the whole game
+	Comment string
+	// Comments on the moves, in the order the moves are written: MoveComments[0] is about the first move.
+	// Deciding not to swap isn't written down, so it can't have a comment. Empty comments aren't written.
+	MoveComments []string
+}
+
+// NewGameRecord creates a record of a game, with the result taken from the board
+func NewGameRecord(game Game, playerOneName string, playerTwoName string) GameRecord {
+	return GameRecord{
+		PlayerOneName: playerOneName,
+		PlayerTwoName: playerTwoName,
+		Winner:        GetGameWinner(game),
+		Game:          game,
+	}
+}
+
+// sgfNode is a node of an SGF game tree. Property values are stored in the order they appear.
+type sgfNode struct {
+	properties map[string][]string
+	keys       []string
+}
+
+func newSGFNode() *sgfNode {
+	return &sgfNode{properties: make(map[string][]string)}
+}
+
+func addSGFProperty(node *sgfNode, key string, value string) {
+	if _, ok := node.properties[key]; !ok {
+		node.keys = append(node.keys, key)
+	}
+	node.properties[key] = append(node.properties[key], value)
+}
+
+func getSGFProperty(node *sgfNode, key string) (string, bool) {
+	values, ok := node.properties[key]
+	if !ok || len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// sgfParser reads an SGF game tree from text, starting at index i
+type sgfParser struct {
+	text string
+	i    int
+}
+
+func skipSGFSpace(parser *sgfParser) {
+	for parser.i < len(parser.text) && isSGFSpace(parser.text[parser.i]) {
+		parser.i++
+	}
+}
+
+// parseSGFMainLine parses the nodes of an SGF game tree, following the first variation at every branch.
+func parseSGFMainLine(text string) (error, []*sgfNode) {
+	parser := &sgfParser{text: text}
+	skipSGFSpace(parser)
+	return parseSGFGameTree(parser)
+}
+
+func parseSGFGameTree(parser *sgfParser) (error, []*sgfNode) {
+	if parser.i >= len(parser.text) || parser.text[parser.i] != '(' {
+		return errors.New("SGF game tree should start with \"(\""), nil
+	}
+	parser.i++
+
+	nodes := make([]*sgfNode, 0)
+	skipSGFSpace(parser)
+	for parser.i < len(parser.text) && parser.text[parser.i] == ';' {
+		parser.i++
+		err, node := parseSGFNode(parser)
+		if err != nil {
+			return err, nil
+		}
+		nodes = append(nodes, node)
+		skipSGFSpace(parser)
+	}
+
+	isFirstVariation := true
+	for parser.i < len(parser.text) && parser.text[parser.i] == '(' {
+		err, variationNodes := parseSGFGameTree(parser)
+		if err != nil {
+			return err, nil
+		}
+		// The first variation is the main line
+		if isFirstVariation {
+			nodes = append(nodes, variationNodes...)
+			isFirstVariation = false
+		}
+		skipSGFSpace(parser)
+	}
+
+	if parser.i >= len(parser.text) || parser.text[parser.i] != ')' {
+		return errors.New("SGF game tree should end with \")\""), nil
+	}
+	parser.i++
+	return nil, nodes
+}
+
+func parseSGFNode(parser *sgfParser) (error, *sgfNode) {
+	node := newSGFNode()
+	for {
+		skipSGFSpace(parser)
+		start := parser.i
+		for parser.i < len(parser.text) && parser.text[parser.i] >= 'A' && parser.text[parser.i] <= 'Z' {
+			parser.i++
+		}
+		if start == parser.i {
+			return nil, node
+		}
+		key := parser.text[start:parser.i]
+
+		numValues := 0
+		for {
+			skipSGFSpace(parser)
+			if parser.i >= len(parser.text) || parser.text[parser.i] != '[' {
+				break
+			}
+			err, value := parseSGFValue(parser)
+			if err != nil {
+				return err, nil
+			}
+			addSGFProperty(node, key, value)
+			numValues++
+		}
+		if numValues == 0 {
+			return fmt.Errorf("SGF property %s has no value", key), nil
+		}
+	}
+}
+
+// parseSGFValue parses a property value, including its brackets
+func parseSGFValue(parser *sgfParser) (error, string) {
+	var value strings.Builder
+	for parser.i++; parser.i < len(parser.text); parser.i++ {
+		switch parser.text[parser.i] {
+		case '\\':
+			parser.i++
+			if parser.i < len(parser.text) {
+				value.WriteByte(parser.text[parser.i])
+			}
+		case ']':
+			parser.i++
+			return nil, value.String()
+		default:
+			value.WriteByte(parser.text[parser.i])
+		}
+	}
+	return errors.New("SGF property value should end with \"]\""), ""
+}
+
+func isSGFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func escapeSGFValue(value string) string {
+	value = strings.Replace(value, "\\", "\\\\", -1)
+	return strings.Replace(value, "]", "\\]", -1)
+}
+
+// parseSGFBoardSize parses SZ[5] (a 5x5 board) or SZ[5:4] (5 columns and 4 rows)
+func parseSGFBoardSize(boardSize string) (error, uint, uint) {
+	dimensions := strings.Split(boardSize, ":")
+	if len(dimensions) == 2 {
+		// SGF lists the columns first
+		return ParseBoardDimensions(dimensions[1] + "x" + dimensions[0])
+	}
+	return ParseBoardDimensions(boardSize)
+}
+
+func formatSGFBoardSize(numRows uint, numCols uint) string {
+	if numRows == numCols {
+		return fmt.Sprint(numRows)
+	}
+	return fmt.Sprintf("%d:%d", numCols, numRows)
+}
+
+// ReadSGF reads a game record, replaying its moves so the game can be stepped through with Undo, Redo and ReplayTo
+func ReadSGF(text string) (error, GameRecord) {
+	err, nodes := parseSGFMainLine(text)
+	if err != nil {
+		return err, GameRecord{}
+	}
+	if len(nodes) == 0 {
+		return errors.New("SGF has no nodes"), GameRecord{}
+	}
+
+	root := nodes[0]
+	if gameNumber, ok := getSGFProperty(root, "GM"); ok && gameNumber != sgfGameNumber {
+		return fmt.Errorf("SGF is for game %s, not Hex", gameNumber), GameRecord{}
+	}
+	if _, ok := getSGFProperty(root, "AE"); ok {
+		return errors.New("SGF can't remove stones with AE"), GameRecord{}
+	}
+	for _, node := range nodes[1:] {
+		for _, setupKey := range []string{"AB", "AW", "AE"} {
+			if _, ok := getSGFProperty(node, setupKey); ok {
+				return errors.New("SGF setup stones are only supported at the start of the game"), GameRecord{}
+			}
+		}
+	}
+
+	numRows, numCols := uint(DefaultBoardSize), uint(DefaultBoardSize)
+	if boardSize, ok := getSGFProperty(root, "SZ"); ok {
+		err, numRows, numCols = parseSGFBoardSize(boardSize)
+		if err != nil {
+			return err, GameRecord{}
+		}
+	}
+
+	// Other programs don't write the swap rule, so without RU, it's taken from the swap move, if there is one
+	swapRule := SwapSidesRule
+	if rule, ok := getSGFProperty(root, "RU"); ok {
+		err, swapRule = ParseSwapRule(rule)
+		if err != nil {
+			return err, GameRecord{}
+		}
+	} else {
+		for _, node := range nodes {
+			if value, ok := getSGFProperty(node, "W"); ok && (value == swapPiecesToken || value == sgfLegacySwapToken) {
+				swapRule = SwapPiecesRule
+			}
+		}
+	}
+	if swapRule == SwapPiecesRule && numRows != numCols {
+		return errors.New("Swapping pieces requires a square board"), GameRecord{}
+	}
+
+	board := NewRectangularBoard(numRows, numCols)
+	for i, setupKey := range sgfSetupKeys {
+		for _, value := range root.properties[setupKey] {
+			err, location := ParseLocation(value, numRows, numCols)
+			if err != nil {
+				return fmt.Errorf("Invalid setup stone %q", value), GameRecord{}
+			}
+			if board[location.Row][location.Col] != 0 {
+				return fmt.Errorf("Setup stone %q is on an occupied square", value), GameRecord{}
+			}
+			board[location.Row][location.Col] = byte(i + 1)
+		}
+	}
+	err, game := NewGameFromBoard(board, swapRule)
+	if err != nil {
+		return err, GameRecord{}
+	}
+	record := GameRecord{
+		Game: game,
+	}
+	record.PlayerOneName, _ = getSGFProperty(root, "PB")
+	record.PlayerTwoName, _ = getSGFProperty(root, "PW")
+	record.Comment, _ = getSGFProperty(root, "C")
+	if result, ok := getSGFProperty(root, "RE"); ok {
+		if strings.HasPrefix(result, "B+") {
+			record.Winner = 1
+		} else if strings.HasPrefix(result, "W+") {
+			record.Winner = 2
+		}
+	}
+
+	for i, node := range nodes {
+		for _, color := range []string{"B", "W"} {
+			value, ok := getSGFProperty(node, color)
+			if !ok {
+				continue
+			}
+			if value == "resign" {
+				continue
+			}
+
+			var move GameMove
+			switch value {
+			case swapSidesToken:
+				move = GameMove{Type: SwitchSidesMove}
+			case swapPiecesToken, sgfLegacySwapToken:
+				move = GameMove{Type: SwapPiecesMove}
+			default:
+				err, move = ParseMove(value, numRows, numCols)
+				if err != nil || move.Type != RegularMove {
+					return fmt.Errorf("Node %d: invalid move %q", i, value), GameRecord{}
+				}
+			}
+
+			expectedColor := "B"
+			if record.Game.CurrentPlayer == 2 {
+				expectedColor = "W"
+			}
+			if color != expectedColor {
+				return fmt.Errorf("Node %d: expected %s to move", i, expectedColor), GameRecord{}
+			}
+
+			err, record.Game = ApplyGameMove(record.Game, move)
+			if err != nil {
+				return fmt.Errorf("Node %d: %s", i, err.Error()), GameRecord{}
+			}
+			if comment, ok := getSGFProperty(node, "C"); ok && i != 0 {
+				numWrittenMoves := countWrittenMoves(GetPlayedMoves(record.Game))
+				for len(record.MoveComments) < numWrittenMoves {
+					record.MoveComments = append(record.MoveComments, "")
+				}
+				record.MoveComments[numWrittenMoves-1] = comment
+			}
+		}
+	}
+
+	return nil, record
+}
+
+// WriteSGF writes a game record with the moves that have been played so far
+func WriteSGF(record GameRecord) string {
+	numRows, numCols := GetBoardDimensions(record.Game.Board)
+
+	var sgf strings.Builder
+	sgf.WriteString("(;FF[4]GM[" + sgfGameNumber + "]AP[hexit]")
+	sgf.WriteString("SZ[" + formatSGFBoardSize(numRows, numCols) + "]")
+	sgf.WriteString("RU[" + FormatSwapRule(record.Game.SwapRule) + "]")
+	if record.PlayerOneName != "" {
+		sgf.WriteString("PB[" + escapeSGFValue(record.PlayerOneName) + "]")
+	}
+	if record.PlayerTwoName != "" {
+		sgf.WriteString("PW[" + escapeSGFValue(record.PlayerTwoName) + "]")
+	}
+	if record.Winner == 1 {
+		sgf.WriteString("RE[B+]")
+	} else if record.Winner == 2 {
+		sgf.WriteString("RE[W+]")
+	}
+	if record.Comment != "" {
+		sgf.WriteString("C[" + escapeSGFValue(record.Comment) + "]")
+	}
+
+	// Replay the game from the start, to find the starting stones and which color made each move
+	err, game := ReplayTo(record.Game, 0)
+	if err != nil {
+		panic(err)
+	}
+	for i, setupKey := range sgfSetupKeys {
+		setupStones := make([]string, 0)
+		for row := uint(0); row < numRows; row++ {
+			for col := uint(0); col < numCols; col++ {
+				if game.Board[row][col] == byte(i+1) {
+					setupStones = append(setupStones, "["+FormatLocation(BoardLocation{Row: row, Col: col})+"]")
+				}
+			}
+		}
+		if len(setupStones) > 0 {
+			sgf.WriteString(setupKey + strings.Join(setupStones, ""))
+		}
+	}
+	numWrittenMoves := 0
+	for _, move := range GetPlayedMoves(record.Game) {
+		color := "B"
+		if game.CurrentPlayer == 2 {
+			color = "W"
+		}
+		switch move.Type {
+		case RegularMove:
+			sgf.WriteString(";" + color + "[" + FormatLocation(move.Location) + "]")
+		case SwitchSidesMove:
+			sgf.WriteString(";" + color + "[" + swapSidesToken + "]")
+		case SwapPiecesMove:
+			sgf.WriteString(";" + color + "[" + swapPiecesToken + "]")
+		case DoNotSwitchSidesMove:
+			// Not switching sides is implied by playing a regular move
+		}
+		if move.Type != DoNotSwitchSidesMove {
+			if numWrittenMoves < len(record.MoveComments) && record.MoveComments[numWrittenMoves] != "" {
+				sgf.WriteString("C[" + escapeSGFValue(record.MoveComments[numWrittenMoves]) + "]")
+			}
+			numWrittenMoves++
+		}
+		err, game = ApplyGameMove(game, move)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	sgf.WriteString(")\n")
+	return sgf.String()
+}
+
+// LoadGameRecord reads a game record from an SGF file
+func LoadGameRecord(filename string) (error, GameRecord) {
+	sgf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err, GameRecord{}
+	}
+	return ReadSGF(string(sgf))
+}
+
+// SaveGameRecord writes a game record to an SGF file
+func SaveGameRecord(filename string, record GameRecord) error {
+	return ioutil.WriteFile(filename, []byte(WriteSGF(record)), 0644)
+}
@@ -0,0 +1,236 @@
+package hexit
+
+import "testing"
+
+func TestReadSGF(t *testing.T) {
+	err, record := ReadSGF("(;FF[4]GM[11]SZ[5]PB[Alice]PW[Bob]RE[W+]\n;B[c3];W[swap-sides];W[b4]\n)")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if record.PlayerOneName != "Alice" || record.PlayerTwoName != "Bob" {
+		t.Error("Expected player names to be read")
+	}
+	if record.Winner != 2 {
+		t.Error("Expected White to have won")
+	}
+
+	playedMoves := GetPlayedMoves(record.Game)
+	expectedMoves := []GameMove{
+		{Type: RegularMove, Location: Move{Row: 2, Col: 2}},
+		{Type: SwitchSidesMove},
+		{Type: RegularMove, Location: Move{Row: 3, Col: 1}},
+	}
+	if len(playedMoves) != len(expectedMoves) {
+		t.Fatalf("Expected %d moves, got %d", len(expectedMoves), len(playedMoves))
+	}
+	for i := range expectedMoves {
+		if playedMoves[i] != expectedMoves[i] {
+			t.Errorf("Move %d is %s instead of %s", i, FormatMove(playedMoves[i]), FormatMove(expectedMoves[i]))
+		}
+	}
+	if !record.Game.SwitchedSides || record.Game.Board[3][1] != 2 {
+		t.Error("Expected White to switch sides and then play b4")
+	}
+}
+
+func TestReadSGFWithSwappedPieces(t *testing.T) {
+	for _, swapToken := range []string{"swap-pieces", "swap"} {
+		err, record := ReadSGF("(;FF[4]GM[11]SZ[5];B[b1];W[" + swapToken + "];B[c3])")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if record.Game.SwapRule != SwapPiecesRule {
+			t.Error("Expected the game to use the swap-pieces rule")
+		}
+		if record.Game.Board[0][1] != 0 || record.Game.Board[1][0] != 2 || record.Game.Board[2][2] != 1 {
+			t.Error("Expected White to swap pieces and then Black to play c3")
+		}
+		if WriteSGF(record) != "(;FF[4]GM[11]AP[hexit]SZ[5]RU[swap-pieces];B[b1];W[swap-pieces];B[c3])\n" {
+			t.Error("Expected swapping pieces to be written as swap-pieces")
+		}
+	}
+}
+
+func TestReadSGFFollowsMainLine(t *testing.T) {
+	err, record := ReadSGF("(;GM[11]SZ[5];B[a1](;W[b2](;B[c3])(;B[d4]))(;W[e5];B[c1]))")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	board := record.Game.Board
+	if board[0][0] != 1 || board[1][1] != 2 || board[2][2] != 1 {
+		t.Error("Expected the first variation to be played")
+	}
+	if board[3][3] != 0 || board[4][4] != 0 || board[0][2] != 0 {
+		t.Error("Expected the other variations to be skipped")
+	}
+}
+
+func TestReadSGFWithRectangularBoard(t *testing.T) {
+	// 5 columns and 4 rows
+	err, record := ReadSGF("(;FF[4]GM[11]SZ[5:4];B[e4])")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	numRows, numCols := GetBoardDimensions(record.Game.Board)
+	if numRows != 4 || numCols != 5 {
+		t.Errorf("Expected a 4x5 board, got %dx%d", numRows, numCols)
+	}
+	if record.Game.Board[3][4] != 1 {
+		t.Error("Expected Black to play e4")
+	}
+}
+
+func TestReadInvalidSGF(t *testing.T) {
+	invalidSGFs := []string{
+		"",
+		"(;GM[1]SZ[19];B[aa])",
+		"(;GM[11]SZ[5];B[c3]",
+		"(;GM[11]SZ[5];B[c3];B[c4])",
+		"(;GM[11]SZ[5];B[c3];W[c3])",
+		"(;GM[11]SZ[5];B[f6])",
+		"(;GM[11]SZ[5]AB[c3]AW[c4];W[d4])",
+		"(;GM[11]SZ[5]AB[c3][c3])",
+		"(;GM[11]SZ[5]AB[f6])",
+		"(;GM[11]SZ[5]AE[c3])",
+		"(;GM[11]SZ[5];B[c3]AW[c4])",
+		"(;GM[11]SZ[5:4];B[c3];W[swap-pieces])",
+		"(;GM[11]SZ[5]RU[pie];B[c3])",
+		"(;GM[11]SZ[5]RU[none];B[c3];W[swap-sides])",
+	}
+	for _, sgf := range invalidSGFs {
+		err, _ := ReadSGF(sgf)
+		if err == nil {
+			t.Errorf("Expected %q to be invalid", sgf)
+		}
+	}
+}
+
+func TestWriteSGF(t *testing.T) {
+	var err error
+	game := NewGameWithBoardDimensions(4, 5)
+	for _, move := range []GameMove{
+		{Type: RegularMove, Location: Move{Row: 0, Col: 0}},
+		{Type: DoNotSwitchSidesMove},
+		{Type: RegularMove, Location: Move{Row: 1, Col: 1}},
+	} {
+		err, game = ApplyGameMove(game, move)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	sgf := WriteSGF(NewGameRecord(game, "Alice", "B]ob"))
+	expectedSGF := "(;FF[4]GM[11]AP[hexit]SZ[5:4]RU[swap-sides]PB[Alice]PW[B\\]ob];B[a1];W[b2])\n"
+	if sgf != expectedSGF {
+		t.Errorf("Expected %q, got %q", expectedSGF, sgf)
+	}
+}
+
+func TestSGFRoundTrip(t *testing.T) {
+	var err error
+	game := NewGame()
+	for _, moveText := range []string{"c3", "swap", "b2", "a1", "b1", "a2", "d1", "a3", "d2", "a4", "e1", "a5"} {
+		err, move := ParseMove(moveText, 5, 5)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		err, game = ApplyGameMove(game, move)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	record := NewGameRecord(game, "", "")
+	if record.Winner == 0 {
+		t.Fatal("Expected the game to be over")
+	}
+
+	err, readRecord := ReadSGF(WriteSGF(record))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if readRecord.Winner != record.Winner {
+		t.Error("Expected the result to be preserved")
+	}
+	if readRecord.Game.Hash != game.Hash || len(GetPlayedMoves(readRecord.Game)) != len(GetPlayedMoves(game)) {
+		t.Error("Expected the game to be preserved")
+	}
+}
+
+// The swap rule changes move numbers and hashes, so it has to survive being written and read back
+func TestSGFRoundTripKeepsSwapRule(t *testing.T) {
+	for _, swapRule := range []SwapRule{SwapSidesRule, SwapPiecesRule, NoSwapRule} {
+		game := NewGameWithRules(5, 5, swapRule)
+		for _, location := range []Move{{Row: 2, Col: 2}, {Row: 1, Col: 3}, {Row: 0, Col: 1}} {
+			var err error
+			err, game = ApplyGameMove(game, GameMove{Type: RegularMove, Location: location})
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+		}
+
+		err, readRecord := ReadSGF(WriteSGF(NewGameRecord(game, "", "")))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if readRecord.Game.SwapRule != swapRule || readRecord.Game.MoveNum != game.MoveNum || readRecord.Game.Hash != game.Hash {
+			t.Errorf("Expected the game with swap rule %s to be preserved", FormatSwapRule(swapRule))
+		}
+	}
+}
+
+func TestSGFRoundTripWithStartingStones(t *testing.T) {
+	err, board := ParsePosition("x----/-o---/-----/-----/-----")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, game := NewGameFromBoard(board, SwapSidesRule)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, game = PlayGameMove(game, 1, 3)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	sgf := WriteSGF(NewGameRecord(game, "", ""))
+	expectedSGF := "(;FF[4]GM[11]AP[hexit]SZ[5]RU[swap-sides]AB[a1]AW[b2];B[d2])\n"
+	if sgf != expectedSGF {
+		t.Errorf("Expected %q, got %q", expectedSGF, sgf)
+	}
+	err, readRecord := ReadSGF(sgf)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if readRecord.Game.Hash != game.Hash || len(GetPlayedMoves(readRecord.Game)) != 1 {
+		t.Error("Expected the starting stones and the move to be preserved")
+	}
+	err, startingGame := ReplayTo(readRecord.Game, 0)
+	if err != nil || FormatPosition(startingGame.Board) != "x----/-o---/-----/-----/-----" {
+		t.Error("Expected the game to start from the setup stones")
+	}
+}
+
+func TestSGFComments(t *testing.T) {
+	err, record := ReadSGF("(;FF[4]GM[11]SZ[5]C[Reviewed];B[c3];W[b4]C[Should have swapped];B[b3]C[Good \\] move])")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if record.Comment != "Reviewed" {
+		t.Errorf("Expected the game comment to be read, got %q", record.Comment)
+	}
+	expectedComments := []string{"", "Should have swapped", "Good ] move"}
+	if len(record.MoveComments) != len(expectedComments) {
+		t.Fatalf("Expected %d move comments, got %d", len(expectedComments), len(record.MoveComments))
+	}
+	for i := range expectedComments {
+		if record.MoveComments[i] != expectedComments[i] {
+			t.Errorf("Expected comment %q on move %d, got %q", expectedComments[i], i+1, record.MoveComments[i])
+		}
+	}
+
+	sgf := WriteSGF(record)
+	expectedSGF := "(;FF[4]GM[11]AP[hexit]SZ[5]RU[swap-sides]C[Reviewed];B[c3];W[b4]C[Should have swapped];B[b3]C[Good \\] move])\n"
+	if sgf != expectedSGF {
+		t.Errorf("Expected %q, got %q", expectedSGF, sgf)
+	}
+}
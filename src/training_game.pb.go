@@ -38,21 +38,26 @@ func (x TrainingGame_Player) String() string {
 	return proto.EnumName(TrainingGame_Player_name, int32(x))
 }
 func (TrainingGame_Player) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_training_game_2456b92fc52b1182, []int{0, 0}
+	return fileDescriptor_training_game_aca79c3e0be31d5f, []int{0, 0}
 }
 
 type TrainingGame struct {
-	MoveSnapshots        []*TrainingGame_MoveSnapshot `protobuf:"bytes,1,rep,name=moveSnapshots,proto3" json:"moveSnapshots,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
-	XXX_unrecognized     []byte                       `json:"-"`
-	XXX_sizecache        int32                        `json:"-"`
+	MoveSnapshots []*TrainingGame_MoveSnapshot `protobuf:"bytes,1,rep,name=moveSnapshots,proto3" json:"moveSnapshots,omitempty"`
+	// Dimensions of the board, as seen by Player 1.
+	// Games recorded before these fields existed were played on a 5x5 board.
+	// Games recorded before numCols existed were played on a square board.
+	NumRows              uint32   `protobuf:"varint,2,opt,name=numRows,proto3" json:"numRows,omitempty"`
+	NumCols              uint32   `protobuf:"varint,3,opt,name=numCols,proto3" json:"numCols,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *TrainingGame) Reset()         { *m = TrainingGame{} }
 func (m *TrainingGame) String() string { return proto.CompactTextString(m) }
 func (*TrainingGame) ProtoMessage()    {}
 func (*TrainingGame) Descriptor() ([]byte, []int) {
-	return fileDescriptor_training_game_2456b92fc52b1182, []int{0}
+	return fileDescriptor_training_game_aca79c3e0be31d5f, []int{0}
 }
 func (m *TrainingGame) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TrainingGame.Unmarshal(m, b)
@@ -79,10 +84,24 @@ func (m *TrainingGame) GetMoveSnapshots() []*TrainingGame_MoveSnapshot {
 	return nil
 }
 
+func (m *TrainingGame) GetNumRows() uint32 {
+	if m != nil {
+		return m.NumRows
+	}
+	return 0
+}
+
+func (m *TrainingGame) GetNumCols() uint32 {
+	if m != nil {
+		return m.NumCols
+	}
+	return 0
+}
+
 type TrainingGame_MoveSnapshot struct {
 	NormalizedVisitCounts []float32           `protobuf:"fixed32,1,rep,packed,name=normalizedVisitCounts,proto3" json:"normalizedVisitCounts,omitempty"`
 	Winner                TrainingGame_Player `protobuf:"varint,2,opt,name=winner,proto3,enum=hexit.TrainingGame_Player" json:"winner,omitempty"`
-	// 25 floats, one per board square.
+	// numRows * numCols floats, one per board square.
 	// 1.0 if occupied, 0.0 if not
 	SquaresOccupiedByMyself      []float32 `protobuf:"fixed32,3,rep,packed,name=squaresOccupiedByMyself,proto3" json:"squaresOccupiedByMyself,omitempty"`
 	SquaresOccupiedByOtherPlayer []float32 `protobuf:"fixed32,4,rep,packed,name=squaresOccupiedByOtherPlayer,proto3" json:"squaresOccupiedByOtherPlayer,omitempty"`
@@ -95,7 +114,7 @@ func (m *TrainingGame_MoveSnapshot) Reset()         { *m = TrainingGame_MoveSnap
 func (m *TrainingGame_MoveSnapshot) String() string { return proto.CompactTextString(m) }
 func (*TrainingGame_MoveSnapshot) ProtoMessage()    {}
 func (*TrainingGame_MoveSnapshot) Descriptor() ([]byte, []int) {
-	return fileDescriptor_training_game_2456b92fc52b1182, []int{0, 0}
+	return fileDescriptor_training_game_aca79c3e0be31d5f, []int{0, 0}
 }
 func (m *TrainingGame_MoveSnapshot) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_TrainingGame_MoveSnapshot.Unmarshal(m, b)
@@ -150,26 +169,28 @@ func init() {
 }
 
 func init() {
-	proto.RegisterFile("src/training_game.proto", fileDescriptor_training_game_2456b92fc52b1182)
-}
-
-var fileDescriptor_training_game_2456b92fc52b1182 = []byte{
-	// 270 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0xd1, 0xb1, 0x4b, 0xc3, 0x40,
-	0x14, 0x06, 0x70, 0x93, 0x6a, 0x86, 0x67, 0x94, 0x70, 0x20, 0x0d, 0xc5, 0x21, 0x74, 0x90, 0x4e,
-	0x11, 0xe2, 0xe2, 0xe0, 0x62, 0x25, 0xd1, 0xa1, 0x21, 0x25, 0x2d, 0x42, 0xa7, 0x72, 0xa6, 0xcf,
-	0xe6, 0x20, 0xb9, 0x8b, 0x77, 0x17, 0x35, 0x8e, 0xfa, 0x8f, 0x0b, 0x6d, 0x28, 0x11, 0xab, 0xdb,
-	0xc1, 0xf7, 0xbb, 0xf7, 0x3d, 0x78, 0xd0, 0x57, 0x32, 0xbb, 0xd4, 0x92, 0x32, 0xce, 0xf8, 0x7a,
-	0xb9, 0xa6, 0x25, 0xfa, 0x95, 0x14, 0x5a, 0x90, 0xa3, 0x1c, 0xdf, 0x99, 0x1e, 0x7e, 0xf5, 0xc0,
-	0x9e, 0xb7, 0xf1, 0x3d, 0x2d, 0x91, 0x44, 0x70, 0x52, 0x8a, 0x57, 0x9c, 0x71, 0x5a, 0xa9, 0x5c,
-	0x68, 0xe5, 0x1a, 0x5e, 0x6f, 0x74, 0x1c, 0x78, 0xfe, 0xc6, 0xfb, 0x5d, 0xeb, 0xc7, 0x1d, 0x98,
-	0xfe, 0xfc, 0x36, 0xf8, 0x34, 0xc1, 0xee, 0xe6, 0xe4, 0x1a, 0xce, 0xb8, 0x90, 0x25, 0x2d, 0xd8,
-	0x07, 0xae, 0x1e, 0x99, 0x62, 0xfa, 0x4e, 0xd4, 0xbc, 0x2d, 0x30, 0xc7, 0xa6, 0x63, 0xa4, 0xfb,
-	0x01, 0x09, 0xc0, 0x7a, 0x63, 0x9c, 0xa3, 0x74, 0x4d, 0xcf, 0x18, 0x9d, 0x06, 0x83, 0x7d, 0xbb,
-	0x4c, 0x0b, 0xda, 0xa0, 0x4c, 0x5b, 0x49, 0x6e, 0xa0, 0xaf, 0x5e, 0x6a, 0x2a, 0x51, 0x25, 0x59,
-	0x56, 0x57, 0x0c, 0x57, 0xe3, 0x26, 0x6e, 0x14, 0x16, 0xcf, 0x6e, 0x6f, 0xd7, 0xf7, 0x17, 0x21,
-	0x11, 0x9c, 0xff, 0x8a, 0x12, 0x9d, 0xa3, 0xdc, 0xb6, 0xb8, 0x87, 0xbb, 0x11, 0xff, 0xba, 0xe1,
-	0x05, 0x58, 0xdb, 0x17, 0x01, 0xb0, 0xe2, 0xc5, 0x2c, 0x9c, 0x44, 0xce, 0x01, 0x71, 0xc0, 0x4e,
-	0xe6, 0x0f, 0x61, 0xba, 0x9c, 0x4e, 0x6e, 0x17, 0x61, 0xea, 0x18, 0x4f, 0xd6, 0xe6, 0x26, 0x57,
-	0xdf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x24, 0xe8, 0x8e, 0x36, 0xae, 0x01, 0x00, 0x00,
+	proto.RegisterFile("src/training_game.proto", fileDescriptor_training_game_aca79c3e0be31d5f)
+}
+
+var fileDescriptor_training_game_aca79c3e0be31d5f = []byte{
+	// 292 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x91, 0x41, 0x4b, 0xc3, 0x30,
+	0x14, 0xc7, 0x6d, 0xab, 0x15, 0x9e, 0x9b, 0x94, 0x80, 0x2c, 0x0c, 0x0f, 0x63, 0x07, 0xd9, 0xa9,
+	0xc2, 0xbc, 0x78, 0xf0, 0xe2, 0xc6, 0xa6, 0x87, 0x8d, 0x8e, 0x6c, 0x08, 0x3b, 0x8d, 0xd8, 0xc5,
+	0x35, 0xd0, 0x26, 0x35, 0x49, 0x9d, 0xf5, 0xe8, 0x97, 0xf2, 0xeb, 0x89, 0x5d, 0x2d, 0x15, 0xa7,
+	0xb7, 0x3c, 0x7e, 0xbf, 0xbc, 0xff, 0x1f, 0x1e, 0xb4, 0xb4, 0x0a, 0x2f, 0x8d, 0xa2, 0x5c, 0x70,
+	0xb1, 0x59, 0x6d, 0x68, 0xc2, 0xfc, 0x54, 0x49, 0x23, 0xd1, 0x51, 0xc4, 0x5e, 0xb9, 0xe9, 0x7e,
+	0x38, 0xd0, 0x58, 0x94, 0xf8, 0x8e, 0x26, 0x0c, 0x8d, 0xa1, 0x99, 0xc8, 0x17, 0x36, 0x17, 0x34,
+	0xd5, 0x91, 0x34, 0x1a, 0x5b, 0x1d, 0xa7, 0x77, 0xd2, 0xef, 0xf8, 0x85, 0xef, 0xd7, 0x5d, 0x7f,
+	0x5a, 0x13, 0xc9, 0xcf, 0x6f, 0x08, 0xc3, 0xb1, 0xc8, 0x12, 0x22, 0xb7, 0x1a, 0xdb, 0x1d, 0xab,
+	0xd7, 0x24, 0xdf, 0x63, 0x49, 0x86, 0x32, 0xd6, 0xd8, 0xa9, 0xc8, 0xd7, 0xd8, 0x7e, 0xb7, 0xa1,
+	0x51, 0xdf, 0x89, 0xae, 0xe1, 0x4c, 0x48, 0x95, 0xd0, 0x98, 0xbf, 0xb1, 0xf5, 0x03, 0xd7, 0xdc,
+	0x0c, 0x65, 0x26, 0xca, 0x52, 0xf6, 0xc0, 0xf6, 0x2c, 0xb2, 0x5f, 0x40, 0x7d, 0x70, 0xb7, 0x5c,
+	0x08, 0xa6, 0x8a, 0xf4, 0xd3, 0x7e, 0x7b, 0x5f, 0xff, 0x59, 0x4c, 0x73, 0xa6, 0x48, 0x69, 0xa2,
+	0x1b, 0x68, 0xe9, 0xe7, 0x8c, 0x2a, 0xa6, 0x83, 0x30, 0xcc, 0x52, 0xce, 0xd6, 0x83, 0x7c, 0x9a,
+	0x6b, 0x16, 0x3f, 0x61, 0xa7, 0xca, 0xfb, 0x4b, 0x41, 0x63, 0x38, 0xff, 0x85, 0x02, 0x13, 0x31,
+	0xb5, 0x4b, 0xc1, 0x87, 0xd5, 0x8a, 0x7f, 0xbd, 0xee, 0x05, 0xb8, 0xbb, 0x17, 0x02, 0x70, 0xa7,
+	0xcb, 0xf9, 0x68, 0x32, 0xf6, 0x0e, 0x90, 0x07, 0x8d, 0x60, 0x71, 0x3f, 0x22, 0xab, 0xd9, 0xe4,
+	0x76, 0x39, 0x22, 0x9e, 0xf5, 0xe8, 0x16, 0x77, 0xbc, 0xfa, 0x1c, 0x00, 0x27, 0xdb, 0xad, 0xdc,
+	0xe2, 0x01, 0x00, 0x00,
 }
@@ -0,0 +1,156 @@
+package hexit
+
+// transpositionEntry is what the nodes of a search tree that have the same position share
+type transpositionEntry struct {
+	// Whether the position has been evaluated yet
+	isEvaluated     bool
+	valueEstimate   float32
+	policyEstimates [][]float32
+	// Total number of visits and total value of all the nodes with this position, if the table shares statistics
+	n uint32
+	w float32
+}
+
+// transpositionTable finds the transposition entry of each position that was reached in a search, by the position's hash
+type transpositionTable struct {
+	entries    map[uint64]*transpositionEntry
+	shareStats bool
+}
+
+// EnableTranspositions makes a search tree share work between nodes that have the same position, reached by different move orders.
+// Such nodes share the evaluator's output, so that each position is only evaluated once.
+// If shareStats is true, they also share their visits and values when the search chooses which move to visit (MCTS over a graph).
+// Each node still backs up its own statistics along the path that was actually taken, and those are what GetBestMove and the other results use.
+// Positions are told apart by their hash, so the tree's game needs an up-to-date Hash.
+// The root's evaluation is taken from its children, so enable transpositions before applying Dirichlet noise.
+func EnableTranspositions(tree *SearchTree, shareStats bool) {
+	tree.transpositions = &transpositionTable{
+		entries:    make(map[uint64]*transpositionEntry),
+		shareStats: shareStats,
+	}
+	linkRootTransposition(tree)
+}
+
+// linkTransposition links a node to the transposition entry of a position.
+// If the node was linked to another entry before, its statistics move to the new entry, and so does the evaluation if the new entry has none.
+func linkTransposition(tree *SearchTree, node *SearchNode, hash uint64) {
+	oldEntry := node.transposition
+	entry := getTransposition(tree.transpositions, hash)
+	if entry == oldEntry {
+		return
+	}
+	if tree.transpositions.shareStats {
+		if oldEntry != nil {
+			oldEntry.n -= node.n
+			oldEntry.w -= node.w
+		}
+		entry.n += node.n
+		entry.w += node.w
+	}
+	if oldEntry != nil && oldEntry.isEvaluated && !entry.isEvaluated {
+		entry.isEvaluated = true
+		entry.valueEstimate = oldEntry.valueEstimate
+		entry.policyEstimates = oldEntry.policyEstimates
+	}
+	node.transposition = entry
+}
+
+// linkRootTransposition links the root node of a tree to its transposition entry.
+// The root was evaluated when it was expanded, so its entry gets the root's evaluation, and transpositions to it don't evaluate it again.
+func linkRootTransposition(tree *SearchTree) {
+	linkTransposition(tree, tree.rootNode, tree.game.Hash)
+	if !tree.rootNode.transposition.isEvaluated && tree.rootNode.firstChild != nil {
+		storeTranspositionEvaluation(tree.rootNode, tree.rootNode.v, getChildPolicyEstimates(tree.rootNode, tree.game.Board))
+	}
+}
+
+// getChildPolicyEstimates rebuilds the policy estimates of an expanded node from the policy estimates of its children.
+// They're normalized over the legal moves, but expandNode normalizes them anyway, so they give the same children.
+func getChildPolicyEstimates(node *SearchNode, board Board) [][]float32 {
+	numRows, numCols := GetBoardDimensions(board)
+	policyEstimates := make([][]float32, numRows)
+	for i := range policyEstimates {
+		policyEstimates[i] = make([]float32, numCols)
+	}
+	for childNode := node.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		policyEstimates[childNode.move.Row][childNode.move.Col] = childNode.p
+	}
+	return policyEstimates
+}
+
+// relinkTranspositions links a node and the nodes below it to the transposition entries of their positions, given the node's game.
+// Deciding whether to swap on move 2 changes the hashes of the positions after it, even though the stones stay the same,
+// so the tree's nodes need new entries after the decision.
+func relinkTranspositions(tree *SearchTree, node *SearchNode, game Game) {
+	if node.transposition == nil {
+		// The node hasn't been visited, so neither have the nodes below it
+		return
+	}
+	linkTransposition(tree, node, game.Hash)
+	for childNode := node.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		childGame := game
+		var err error
+		if CanSwap(childGame) {
+			// Like selectLeaf, skip the swap decision
+			err, childGame = DoNotSwitchSides(childGame)
+			if err != nil {
+				panic(err)
+			}
+		}
+		relinkTranspositions(tree, childNode, Game{
+			CurrentPlayer: OtherPlayer(childGame.CurrentPlayer),
+			MoveNum:       childGame.MoveNum + 1,
+			SwitchedSides: childGame.SwitchedSides,
+			SwapRule:      childGame.SwapRule,
+			Hash:          childGame.Hash ^ getRegularMoveHashChange(childGame, childNode.move.Row, childNode.move.Col),
+		})
+	}
+}
+
+// getTransposition gets the transposition entry of a position, creating it if the position wasn't reached before
+func getTransposition(transpositions *transpositionTable, hash uint64) *transpositionEntry {
+	entry, ok := transpositions.entries[hash]
+	if !ok {
+		entry = &transpositionEntry{}
+		transpositions.entries[hash] = entry
+	}
+	return entry
+}
+
+// isSharingStats checks whether a tree shares statistics between nodes that have the same position
+func isSharingStats(tree *SearchTree) bool {
+	return tree.transpositions != nil && tree.transpositions.shareStats
+}
+
+// getSelectionNode gets the node whose statistics are used to choose which move to visit.
+// If the tree shares statistics between transpositions, it's a copy of the node with the statistics of its position.
+func getSelectionNode(tree *SearchTree, node *SearchNode) *SearchNode {
+	if !isSharingStats(tree) || node.transposition == nil || node.transposition.n == 0 {
+		return node
+	}
+	sharedNode := *node
+	sharedNode.n = node.transposition.n
+	sharedNode.w = node.transposition.w
+	sharedNode.q = sharedNode.w / float32(sharedNode.n)
+	return &sharedNode
+}
+
+// expandFromTransposition expands a leaf node with the evaluation of its position, if another node with the same position was evaluated.
+// It reports whether the node was expanded.
+func expandFromTransposition(node *SearchNode, board Board) bool {
+	if node.transposition == nil || !node.transposition.isEvaluated {
+		return false
+	}
+	expandNode(node, board, node.transposition.valueEstimate, node.transposition.policyEstimates)
+	return true
+}
+
+// storeTranspositionEvaluation stores the evaluation of a node's position, for other nodes with the same position
+func storeTranspositionEvaluation(node *SearchNode, valueEstimate float32, policyEstimates [][]float32) {
+	if node.transposition == nil {
+		return
+	}
+	node.transposition.isEvaluated = true
+	node.transposition.valueEstimate = valueEstimate
+	node.transposition.policyEstimates = policyEstimates
+}
@@ -0,0 +1,177 @@
+package hexit
+
+import (
+	"math"
+	"testing"
+)
+
+// countNodes counts the nodes of a tree that were expanded with an evaluation, and adds up the visits of the nodes of each position
+func countNodes(node *SearchNode, visitsByPosition map[*transpositionEntry]uint32) int {
+	numExpandedNodes := 0
+	if node.firstChild != nil {
+		numExpandedNodes++
+	}
+	if node.transposition != nil {
+		visitsByPosition[node.transposition] += node.n
+	}
+	for childNode := node.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		numExpandedNodes += countNodes(childNode, visitsByPosition)
+	}
+	return numExpandedNodes
+}
+
+func TestTranspositionsShareEvaluations(t *testing.T) {
+	numEvaluations := 0
+	countEvaluations := func(board Board, player byte) (float32, [][]float32) {
+		numEvaluations++
+		return EvaluatePositionUniformly(board, player)
+	}
+
+	game := NewGameWithRules(3, 3, NoSwapRule)
+	tree := NewSearchTree(countEvaluations, game)
+	EnableTranspositions(&tree, false)
+	for i := 0; i < 3000; i++ {
+		DoVisit(&tree, countEvaluations)
+	}
+
+	numExpandedNodes := countNodes(tree.rootNode, make(map[*transpositionEntry]uint32))
+	numEvaluatedPositions := 0
+	for _, entry := range tree.transpositions.entries {
+		if entry.isEvaluated {
+			numEvaluatedPositions++
+		}
+	}
+	// The root was evaluated when the tree was created, and its entry has that evaluation
+	if numEvaluations != numEvaluatedPositions {
+		t.Errorf("Expected each position to be evaluated once, got %d evaluations of %d positions", numEvaluations, numEvaluatedPositions)
+	}
+	if numEvaluations >= numExpandedNodes {
+		t.Errorf("Expected fewer evaluations than the %d expanded nodes, got %d", numExpandedNodes, numEvaluations)
+	}
+}
+
+func TestTranspositionsShareStats(t *testing.T) {
+	game := NewGameWithRules(3, 3, NoSwapRule)
+	tree := NewSearchTree(EvaluatePositionRandomly, game)
+	EnableTranspositions(&tree, true)
+	for i := 0; i < 1000; i++ {
+		DoVisit(&tree, EvaluatePositionRandomly)
+	}
+	DoParallelVisits(&tree, EvaluatePositionRandomly, 1000, 4)
+	checkVisitCounts(t, tree.rootNode, true)
+
+	// Each visit to a node is also a visit to its position
+	visitsByPosition := make(map[*transpositionEntry]uint32)
+	countNodes(tree.rootNode, visitsByPosition)
+	for entry, numVisits := range visitsByPosition {
+		if entry.n != numVisits {
+			t.Errorf("Expected a position to have %d visits, got %d", numVisits, entry.n)
+		}
+		if math.IsNaN(float64(entry.w)) {
+			t.Error("Expected the total value of a position to be a number")
+		}
+	}
+}
+
+func TestTranspositionsFindWinningMove(t *testing.T) {
+	err, board := ParsePosition("xo---/xo---/xo---/xo---/-----")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err, game := NewGameFromBoard(board, SwapSidesRule)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, shareStats := range []bool{false, true} {
+		tree := NewSearchTree(EvaluatePositionUniformly, game)
+		EnableTranspositions(&tree, shareStats)
+		DoBatchedVisits(&tree, NewBatchEvaluator(EvaluatePositionUniformly), 1000, 8)
+		bestMove := GetBestMove(&tree)
+		if bestMove.Row != 4 || bestMove.Col != 0 {
+			t.Error("Failed to find the winning move")
+		}
+
+		err = AdvanceSearchTree(&tree, EvaluatePositionUniformly, GameMove{Type: RegularMove, Location: Move{Row: 0, Col: 4}})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if tree.transpositions == nil || tree.rootNode.transposition == nil {
+			t.Error("Expected the tree to keep its transposition table")
+		}
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+}
+
+// checkTranspositionLinks checks that each visited node is linked to the entry of its position, by replaying the moves to it
+func checkTranspositionLinks(t *testing.T, tree *SearchTree, node *SearchNode, game Game) {
+	if node.transposition == nil {
+		return
+	}
+	if node.transposition != tree.transpositions.entries[game.Hash] {
+		t.Fatalf("Expected the node after %v to be linked to the entry of its position", GetPlayedMoves(game))
+	}
+	for childNode := node.firstChild; childNode != nil; childNode = childNode.nextSibling {
+		err, childGame := ApplyGameMove(game, GameMove{Type: RegularMove, Location: childNode.move})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		checkTranspositionLinks(t, tree, childNode, childGame)
+	}
+}
+
+func TestAdvanceSearchTreeRelinksTranspositionsAfterSwapDecision(t *testing.T) {
+	for _, move := range []GameMove{{Type: SwitchSidesMove}, {Type: DoNotSwitchSidesMove}} {
+		err, game := PlayGameMove(NewGameWithBoardSize(4), 1, 2)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		tree := NewSearchTree(EvaluatePositionRandomly, game)
+		EnableTranspositions(&tree, true)
+		for i := 0; i < 500; i++ {
+			DoVisit(&tree, EvaluatePositionRandomly)
+		}
+
+		err = AdvanceSearchTree(&tree, EvaluatePositionRandomly, move)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		checkTranspositionLinks(t, &tree, tree.rootNode, tree.game)
+		if !tree.rootNode.transposition.isEvaluated {
+			t.Error("Expected the root's entry to keep the root's evaluation")
+		}
+
+		// The statistics of the entries should still match the nodes linked to them
+		for i := 0; i < 500; i++ {
+			DoVisit(&tree, EvaluatePositionRandomly)
+		}
+		visitsByPosition := make(map[*transpositionEntry]uint32)
+		countNodes(tree.rootNode, visitsByPosition)
+		for entry, numVisits := range visitsByPosition {
+			if entry.n != numVisits {
+				t.Errorf("Expected a position to have %d visits, got %d", numVisits, entry.n)
+			}
+		}
+	}
+}
+
+func TestEnableTranspositionsStoresRootEvaluation(t *testing.T) {
+	tree := NewSearchTree(EvaluatePositionUniformly, NewGameWithBoardSize(3))
+	EnableTranspositions(&tree, false)
+	entry := tree.rootNode.transposition
+	if !entry.isEvaluated || entry.valueEstimate != tree.rootNode.v {
+		t.Fatal("Expected the root's entry to have the root's evaluation")
+	}
+
+	// Expanding another node with the entry's evaluation gives it the same children as the root
+	node := NewSearchNode(nil, Move{})
+	node.transposition = entry
+	if !expandFromTransposition(&node, tree.game.Board) {
+		t.Fatal("Expected the node to be expanded from the root's entry")
+	}
+	for childNode, rootChildNode := node.firstChild, tree.rootNode.firstChild; rootChildNode != nil; childNode, rootChildNode = childNode.nextSibling, rootChildNode.nextSibling {
+		if childNode.move != rootChildNode.move || math.Abs(float64(childNode.p-rootChildNode.p)) > 1e-6 {
+			t.Error("Expected the same children as the root")
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package hexit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TreeExportOptions limits how much of a search tree is exported, so the output stays readable
+type TreeExportOptions struct {
+	// Deepest level of nodes to export, where the root's children are at depth 1. 0 means no limit.
+	MaxDepth int
+	// Nodes with fewer visits are left out, along with everything below them
+	MinVisits uint32
+}
+
+// ExportedNode is a node of a search tree, as exported by ExportTree.
+// Statistics that a node doesn't have yet, such as V before it's evaluated, are nil.
+type ExportedNode struct {
+	// The move that led to this node, such as "c3", or empty for the root
+	Move string `json:"move,omitempty"`
+	// Number of visits
+	N uint32 `json:"n"`
+	// Average value, from the point of view of the player who made the move
+	Q float32 `json:"q"`
+	// Policy estimate from the evaluator
+	P *float32 `json:"p,omitempty"`
+	// Value estimate from the evaluator
+	V *float32 `json:"v,omitempty"`
+	// Exploration bonus that the search gives the move
+	U *float32 `json:"u,omitempty"`
+	// Whether the move ends the game
+	Terminal bool `json:"terminal"`
+	// Children that were exported, with the most visited first
+	Children []ExportedNode `json:"children,omitempty"`
+}
+
+// optionalStat returns nil for statistics that haven't been set
+func optionalStat(value float32) *float32 {
+	if math.IsNaN(float64(value)) {
+		return nil
+	}
+	return &value
+}
+
+func exportNode(node *SearchNode, depth int, options TreeExportOptions) ExportedNode {
+	exportedNode := ExportedNode{
+		N:        node.n,
+		Q:        node.q,
+		P:        optionalStat(node.p),
+		V:        optionalStat(node.v),
+		Terminal: node.isTerminal,
+	}
+	if node.parent != nil {
+		exportedNode.Move = FormatLocation(node.move)
+		exportedNode.U = optionalStat(calculateUctU(node, uint(node.parent.n)))
+	}
+	if options.MaxDepth != 0 && depth >= options.MaxDepth {
+		return exportedNode
+	}
+	for _, childNode := range getSortedChildren(node) {
+		if childNode.n < options.MinVisits {
+			continue
+		}
+		exportedNode.Children = append(exportedNode.Children, exportNode(childNode, depth+1, options))
+	}
+	return exportedNode
+}
+
+// ExportTree copies the statistics of a search tree, down to the limits given by the options
+func ExportTree(tree *SearchTree, options TreeExportOptions) ExportedNode {
+	return exportNode(tree.rootNode, 0, options)
+}
+
+// ExportTreeJSON writes a search tree as indented JSON, in the format of ExportedNode
+func ExportTreeJSON(tree *SearchTree, options TreeExportOptions) string {
+	bytes, err := json.MarshalIndent(ExportTree(tree, options), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return string(bytes) + "\n"
+}
+
+func formatDOTLabel(node ExportedNode) string {
+	move := node.Move
+	if move == "" {
+		move = "root"
+	}
+	lines := []string{move, fmt.Sprintf("N=%d Q=%.3f", node.N, node.Q)}
+	stats := make([]string, 0, 3)
+	for _, stat := range []struct {
+		name  string
+		value *float32
+	}{{"P", node.P}, {"V", node.V}, {"U", node.U}} {
+		if stat.value != nil {
+			stats = append(stats, fmt.Sprintf("%s=%.3f", stat.name, *stat.value))
+		}
+	}
+	if len(stats) > 0 {
+		lines = append(lines, strings.Join(stats, " "))
+	}
+	return strings.Join(lines, "\\n")
+}
+
+// writeDOTNode writes a node and the edges to its children, and returns the next unused node ID
+func writeDOTNode(dot *strings.Builder, node ExportedNode, id int) int {
+	shape := "ellipse"
+	if node.Terminal {
+		shape = "box"
+	}
+	fmt.Fprintf(dot, "  n%d [label=\"%s\", shape=%s];\n", id, formatDOTLabel(node), shape)
+	nextID := id + 1
+	for _, child := range node.Children {
+		fmt.Fprintf(dot, "  n%d -> n%d;\n", id, nextID)
+		nextID = writeDOTNode(dot, child, nextID)
+	}
+	return nextID
+}
+
+// ExportTreeDOT writes a search tree as a Graphviz graph. Terminal nodes are drawn as boxes.
+func ExportTreeDOT(tree *SearchTree, options TreeExportOptions) string {
+	var dot strings.Builder
+	dot.WriteString("digraph SearchTree {\n")
+	writeDOTNode(&dot, ExportTree(tree, options), 0)
+	dot.WriteString("}\n")
+	return dot.String()
+}
@@ -0,0 +1,93 @@
+package hexit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func getExportedTreeDepth(node ExportedNode) int {
+	maxDepth := 0
+	for _, child := range node.Children {
+		if depth := 1 + getExportedTreeDepth(child); depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	return maxDepth
+}
+
+func TestExportTree(t *testing.T) {
+	tree := NewSearchTree(EvaluatePositionUniformly, NewGameWithBoardSize(3))
+	for i := 0; i < 200; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+
+	root := ExportTree(&tree, TreeExportOptions{})
+	if root.Move != "" || root.N != 200 || root.P != nil || root.U != nil {
+		t.Error("Expected the root to have visits but no move, policy or exploration bonus")
+	}
+	if len(root.Children) != 9 || root.Children[0].Move == "" || root.Children[0].P == nil {
+		t.Error("Expected every move from the root to be exported")
+	}
+	if getExportedTreeDepth(root) < 3 {
+		t.Error("Expected the whole tree to be exported")
+	}
+
+	if getExportedTreeDepth(ExportTree(&tree, TreeExportOptions{MaxDepth: 2})) != 2 {
+		t.Error("Expected the tree to be cut off at depth 2")
+	}
+	limitedRoot := ExportTree(&tree, TreeExportOptions{MinVisits: 10})
+	if len(limitedRoot.Children) == 0 {
+		t.Error("Expected some moves to have at least 10 visits")
+	}
+	var checkMinVisits func(node ExportedNode)
+	checkMinVisits = func(node ExportedNode) {
+		for _, child := range node.Children {
+			if child.N < 10 {
+				t.Error("Expected nodes with fewer than 10 visits to be left out")
+			}
+			checkMinVisits(child)
+		}
+	}
+	checkMinVisits(limitedRoot)
+}
+
+func TestExportTreeJSON(t *testing.T) {
+	tree := NewSearchTree(EvaluatePositionUniformly, NewGameWithBoardSize(3))
+	for i := 0; i < 50; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+
+	var root ExportedNode
+	if err := json.Unmarshal([]byte(ExportTreeJSON(&tree, TreeExportOptions{MaxDepth: 1})), &root); err != nil {
+		t.Fatal(err.Error())
+	}
+	if root.N != 50 || len(root.Children) != 9 || len(root.Children[0].Children) != 0 {
+		t.Error("Expected the root and its children")
+	}
+}
+
+func TestExportTreeDOT(t *testing.T) {
+	tree := NewSearchTree(EvaluatePositionUniformly, NewGameWithBoardSize(2))
+	for i := 0; i < 100; i++ {
+		DoVisit(&tree, EvaluatePositionUniformly)
+	}
+
+	dot := ExportTreeDOT(&tree, TreeExportOptions{MaxDepth: 1})
+	if !strings.HasPrefix(dot, "digraph SearchTree {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Error("Expected a Graphviz graph")
+	}
+	// The root and its 4 children
+	if strings.Count(dot, "[label=") != 5 || strings.Count(dot, " -> ") != 4 {
+		t.Errorf("Expected 5 nodes and 4 edges, got:\n%s", dot)
+	}
+	if !strings.Contains(dot, "n0 [label=\"root\\nN=100") {
+		t.Error("Expected the root to be labelled with its visits")
+	}
+
+	// On a 2x2 board, some moves end the game on move 3
+	dot = ExportTreeDOT(&tree, TreeExportOptions{})
+	if !strings.Contains(dot, "shape=box") {
+		t.Error("Expected terminal nodes to be drawn as boxes")
+	}
+}
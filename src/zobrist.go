@@ -0,0 +1,103 @@
+package hexit
+
+// Zobrist hashing: every feature of a game state has a random 64-bit key,
+// and a state's hash is the XOR of the keys of all its features.
+// Playing a move only changes a few features, so the hash can be updated incrementally.
+//
+// The keys are generated from a fixed seed, so hashes are the same in every run
+// and can be stored in files or databases.
+
+// The largest possible move number: every square is filled, plus the side-switching move
+const maxMoveNum = MaxBoardSize*MaxBoardSize + 2
+
+var (
+	// stoneKeys[player-1][row*MaxBoardSize+col]
+	stoneKeys [2][MaxBoardSize * MaxBoardSize]uint64
+	// boardDimensionKeys[0] is indexed by the number of rows, and boardDimensionKeys[1] by the number of columns
+	boardDimensionKeys [2][MaxBoardSize + 1]uint64
+	moveNumKeys        [maxMoveNum + 1]uint64
+	playerTwoToMoveKey uint64
+	switchedSidesKey   uint64
+	// swapRuleKeys is indexed by SwapRule. The key for SwapSidesRule is 0, so the rule doesn't change its games' hashes.
+	swapRuleKeys [NoSwapRule + 1]uint64
+)
+
+func init() {
+	// splitmix64
+	state := uint64(0x6865786974) // "hexit"
+	nextKey := func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+
+	for player := range stoneKeys {
+		for square := range stoneKeys[player] {
+			stoneKeys[player][square] = nextKey()
+		}
+	}
+	for dimension := range boardDimensionKeys {
+		for size := range boardDimensionKeys[dimension] {
+			boardDimensionKeys[dimension][size] = nextKey()
+		}
+	}
+	for moveNum := range moveNumKeys {
+		moveNumKeys[moveNum] = nextKey()
+	}
+	playerTwoToMoveKey = nextKey()
+	switchedSidesKey = nextKey()
+	for swapRule := SwapPiecesRule; swapRule <= NoSwapRule; swapRule++ {
+		swapRuleKeys[swapRule] = nextKey()
+	}
+}
+
+func getStoneKey(player byte, row uint, col uint) uint64 {
+	return stoneKeys[player-1][row*MaxBoardSize+col]
+}
+
+func getMoveNumKey(moveNum int) uint64 {
+	if moveNum < 0 || moveNum > maxMoveNum {
+		panic("Invalid move number")
+	}
+	return moveNumKeys[moveNum]
+}
+
+func getCurrentPlayerKey(player byte) uint64 {
+	if player == 2 {
+		return playerTwoToMoveKey
+	}
+	return 0
+}
+
+func getSwitchedSidesKey(switchedSides bool) uint64 {
+	if switchedSides {
+		return switchedSidesKey
+	}
+	return 0
+}
+
+func getSwapRuleKey(swapRule SwapRule) uint64 {
+	return swapRuleKeys[swapRule]
+}
+
+// ComputeGameHash computes the hash of a game state from scratch.
+// Games created with NewGame and the move functions keep their Hash up to date,
+// so this is only needed after changing a Game's fields directly.
+func ComputeGameHash(game Game) uint64 {
+	numRows, numCols := GetBoardDimensions(game.Board)
+	hash := boardDimensionKeys[0][numRows] ^ boardDimensionKeys[1][numCols]
+	for row := uint(0); row < numRows; row++ {
+		for col := uint(0); col < numCols; col++ {
+			if game.Board[row][col] != 0 {
+				hash ^= getStoneKey(game.Board[row][col], row, col)
+			}
+		}
+	}
+	hash ^= getMoveNumKey(game.MoveNum)
+	hash ^= getCurrentPlayerKey(game.CurrentPlayer)
+	hash ^= getSwitchedSidesKey(game.SwitchedSides)
+	hash ^= getSwapRuleKey(game.SwapRule)
+	return hash
+}
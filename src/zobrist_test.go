@@ -0,0 +1,108 @@
+package hexit
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNewGameHash(t *testing.T) {
+	game := NewGame()
+	if game.Hash != ComputeGameHash(game) {
+		t.Error("New game should start with an up-to-date hash")
+	}
+	if game.Hash == NewGameWithBoardSize(7).Hash {
+		t.Error("Games on different board sizes should have different hashes")
+	}
+	if NewGameWithBoardDimensions(4, 5).Hash == NewGameWithBoardDimensions(5, 4).Hash {
+		t.Error("Rectangular boards with rows and columns swapped should have different hashes")
+	}
+}
+
+// The incremental hash should always match the hash computed from scratch
+func TestIncrementalHashMatchesComputedHash(t *testing.T) {
+	random := rand.New(rand.NewSource(0))
+	var err error
+	for gameNum := 0; gameNum < 50; gameNum++ {
+		game := NewGameWithBoardDimensions(4+uint(gameNum%3), 5)
+		numRows, numCols := GetBoardDimensions(game.Board)
+		squares := random.Perm(int(numRows * numCols))
+		for GetWinner(game.Board) == 0 {
+			if game.MoveNum == 2 {
+				if random.Intn(2) == 0 {
+					err, game = SwitchSides(game)
+				} else {
+					err, game = DoNotSwitchSides(game)
+				}
+			} else {
+				square := uint(squares[0])
+				squares = squares[1:]
+				err, game = PlayGameMove(game, square/numCols, square%numCols)
+			}
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if game.Hash != ComputeGameHash(game) {
+				t.Fatalf("Incremental hash doesn't match on move %d", game.MoveNum)
+			}
+		}
+	}
+}
+
+func TestHashIncludesSwitchedSides(t *testing.T) {
+	game := NewGame()
+	err, game := PlayGameMove(game, 2, 2)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	err, switchedGame := SwitchSides(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	err, notSwitchedGame := DoNotSwitchSides(game)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if switchedGame.Hash == notSwitchedGame.Hash {
+		t.Error("Switching sides should change the hash")
+	}
+	if game.Hash == notSwitchedGame.Hash {
+		t.Error("The side-switching decision should change the hash, even if Player 2 doesn't switch sides")
+	}
+}
+
+func TestHashIsIndependentOfMoveOrder(t *testing.T) {
+	moves := []Move{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 2}, {Row: 3, Col: 3}}
+	playMoves := func(moveOrder []int) Game {
+		game := NewGame()
+		var err error
+		for _, moveIndex := range moveOrder {
+			if game.MoveNum == 2 {
+				err, game = DoNotSwitchSides(game)
+				if err != nil {
+					t.Fatal(err.Error())
+				}
+			}
+			err, game = PlayGameMove(game, moves[moveIndex].Row, moves[moveIndex].Col)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+		}
+		return game
+	}
+
+	// Player 1 plays moves 0 and 2, and Player 2 plays moves 1 and 3, in different orders
+	game1 := playMoves([]int{0, 1, 2, 3})
+	game2 := playMoves([]int{2, 3, 0, 1})
+	game3 := playMoves([]int{0, 3, 2, 1})
+	if game1.Hash != game2.Hash || game1.Hash != game3.Hash {
+		t.Error("The same position reached with a different move order should have the same hash")
+	}
+
+	// Same squares, but the players' stones are exchanged
+	game4 := playMoves([]int{1, 0, 3, 2})
+	if game1.Hash == game4.Hash {
+		t.Error("Positions with different stones should have different hashes")
+	}
+}